@@ -11,32 +11,68 @@ import (
 
 	"github.com/hashicorp/errwrap"
 	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/auth"
 	"github.com/wgentry22/agora/modules/broker"
 	"github.com/wgentry22/agora/modules/heartbeat"
 	"github.com/wgentry22/agora/modules/logg"
 	"github.com/wgentry22/agora/modules/orm"
+	"github.com/wgentry22/agora/modules/rpc"
+	"github.com/wgentry22/agora/modules/tenancy"
 	"github.com/wgentry22/agora/types/config"
+	"google.golang.org/grpc"
 )
 
 var (
-	logger    logg.Logger
-	consumer  broker.Consumer
-	publisher broker.Publisher
+	ErrGRPCDisabled = errors.New("gRPC server is disabled - set `grpc.enabled = true` to register services")
+	logger          logg.Logger
+	consumer        broker.Consumer
+	publisher       broker.Publisher
 )
 
 type Application struct {
 	errors chan error
 	conf   config.Application
 	router api.Router
+	rpc    *rpc.Server
 	quit   chan os.Signal
 }
 
 func (a *Application) RegisterController(controllers ...api.Controller) {
 	for _, controller := range controllers {
+		a.prepareController(&controller)
 		a.router.Register(controller)
 	}
 }
 
+func (a *Application) RegisterVersionedController(version string, controllers ...api.Controller) {
+	for _, controller := range controllers {
+		a.prepareController(&controller)
+		a.router.RegisterVersion(version, controller)
+	}
+}
+
+func (a *Application) RegisterGRPCService(desc *grpc.ServiceDesc, impl interface{}) {
+	if a.rpc == nil {
+		panic(ErrGRPCDisabled)
+	}
+
+	a.rpc.RegisterService(desc, impl)
+}
+
+func (a *Application) prepareController(controller *api.Controller) {
+	if a.conf.Auth().ProtectAll {
+		controller.RequiresAuth()
+	}
+
+	if a.conf.API().TLS.IsMutual() {
+		controller.Use(auth.ClientCertificateMiddleware)
+	}
+
+	if a.conf.Tenancy().Enabled {
+		controller.Use(tenancy.Middleware(a.conf.Tenancy()))
+	}
+}
+
 func (a *Application) Errors() <-chan error {
 	return a.errors
 }
@@ -49,6 +85,10 @@ func (a *Application) Router() http.Handler {
 	return a.router.Handler()
 }
 
+func (a *Application) GRPCServer() *rpc.Server {
+	return a.rpc
+}
+
 func (a *Application) Publisher() broker.Publisher {
 	return publisher
 }
@@ -83,6 +123,33 @@ func (a *Application) Setup() {
 	orm.RegisterPulser()
 	orm.RegisterPacer()
 
+	if a.conf.Tenancy().Enabled {
+		orm.UseTenancy(a.conf.Tenancy())
+	}
+
+	if a.conf.Auth().IsEnabled() {
+		auth.Use(a.conf.Auth())
+		a.router.UseAuthenticator(auth.RequiresTokenMiddleware)
+
+		if a.conf.Auth().Cache.Enabled {
+			auth.RegisterPacer()
+		}
+	}
+
+	if pacer := a.router.Pacer(); pacer != nil {
+		heartbeat.RegisterPacers(pacer)
+	}
+
+	if a.conf.GRPC().Enabled {
+		var verifier auth.TokenVerifier
+		if a.conf.Auth().IsEnabled() {
+			verifier = auth.Verifier()
+		}
+
+		a.rpc = rpc.NewServer(a.conf.GRPC(), a.conf.Info(), verifier)
+		heartbeat.RegisterPacers(a.rpc.Pacer())
+	}
+
 	a.router.Register(heartbeat.NewHeartbeatController(a.conf.Heartbeat()))
 
 	if a.conf.Broker().Role == config.BrokerRoleProducer {
@@ -105,11 +172,19 @@ func (a *Application) Start() {
 	}()
 
 	go func() {
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := a.router.ListenAndServe(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			a.errors <- err
 		}
 	}()
 
+	if a.rpc != nil {
+		go func() {
+			if err := a.rpc.ListenAndServe(); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				a.errors <- err
+			}
+		}()
+	}
+
 	if consumer != nil {
 		go consumer.Start()
 
@@ -134,6 +209,19 @@ func (a *Application) Start() {
 	withTimeout, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if err := a.router.CloseStreams(withTimeout); err != nil {
+		logger.WithError(err).Warn("Streams did not close before timeout")
+	}
+
+	if a.rpc != nil {
+		rpcTimeout, rpcCancel := context.WithTimeout(context.Background(), a.conf.GRPC().ShutdownTimeout)
+		defer rpcCancel()
+
+		if err := a.rpc.Shutdown(rpcTimeout); err != nil {
+			logger.WithError(err).Warn("gRPC server did not stop gracefully before timeout")
+		}
+	}
+
 	if err := server.Shutdown(withTimeout); err != nil {
 		logger.WithError(err).Panic("Failed to shutdown server")
 	}
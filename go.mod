@@ -5,6 +5,7 @@ go 1.15
 require (
 	firebase.google.com/go v3.13.0+incompatible
 	firebase.google.com/go/v4 v4.2.0
+	github.com/andybalholm/brotli v1.0.4
 	github.com/confluentinc/confluent-kafka-go v1.5.2 // indirect
 	github.com/docker/go-connections v0.4.0
 	github.com/fsnotify/fsnotify v1.4.9
@@ -21,6 +22,7 @@ require (
 	github.com/onsi/gomega v1.10.1
 	github.com/pelletier/go-toml v1.8.1
 	github.com/prometheus/client_golang v1.9.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/sirupsen/logrus v1.6.0
 	github.com/testcontainers/testcontainers-go v0.9.0
 	github.com/ugorji/go v1.2.3 // indirect
@@ -29,6 +31,7 @@ require (
 	golang.org/x/text v0.3.5 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 	google.golang.org/api v0.17.0
+	google.golang.org/grpc v1.29.1
 	google.golang.org/protobuf v1.25.0 // indirect
 	gopkg.in/confluentinc/confluent-kafka-go.v1 v1.5.2
 	gopkg.in/yaml.v2 v2.4.0 // indirect
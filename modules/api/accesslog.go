@@ -0,0 +1,86 @@
+package api
+
+import (
+  "math/rand"
+  "net/http"
+  "path"
+  "strings"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  accessLogSample = rand.Float64
+)
+
+func AccessLogMiddleware(conf config.AccessLog, pathPrefixes ...string) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    if isExcludedPath(conf.Exclude, pathPrefixes, c.Request.URL.Path) {
+      c.Next()
+
+      return
+    }
+
+    start := time.Now()
+
+    c.Next()
+
+    status := c.Writer.Status()
+    if status < http.StatusInternalServerError && accessLogSample() >= conf.SampleRate {
+      return
+    }
+
+    logger := logg.Root().
+      WithContext(c.Request.Context()).
+      WithField("method", c.Request.Method).
+      WithField("route", routeTemplate(c)).
+      WithField("path", c.Request.URL.Path).
+      WithField("status", status).
+      WithField("latency_ms", float64(time.Since(start).Nanoseconds())/1e6).
+      WithField("bytes", c.Writer.Size()).
+      WithField("client_ip", c.ClientIP())
+
+    switch {
+    case status >= http.StatusInternalServerError:
+      logger.Error("Request completed")
+    case status >= http.StatusBadRequest:
+      logger.Warn("Request completed")
+    default:
+      logger.Info("Request completed")
+    }
+  }
+}
+
+func isExcludedPath(patterns, pathPrefixes []string, requestPath string) bool {
+  candidates := []string{requestPath}
+  for _, pathPrefix := range pathPrefixes {
+    if pathPrefix != "" && strings.HasPrefix(requestPath, pathPrefix) {
+      candidates = append(candidates, "/"+strings.TrimLeft(strings.TrimPrefix(requestPath, pathPrefix), "/"))
+    }
+  }
+
+  for _, pattern := range patterns {
+    for _, candidate := range candidates {
+      if matchesPathPattern(pattern, candidate) {
+        return true
+      }
+    }
+  }
+
+  return false
+}
+
+func matchesPathPattern(pattern, requestPath string) bool {
+  if strings.HasSuffix(pattern, "/*") {
+    base := strings.TrimSuffix(pattern, "/*")
+
+    return requestPath == base || strings.HasPrefix(requestPath, base+"/")
+  }
+
+  matched, err := path.Match(pattern, requestPath)
+
+  return err == nil && matched
+}
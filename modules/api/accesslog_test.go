@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Access logging", func() {
+	var (
+		logFile *os.File
+		router  api.Router
+	)
+
+	entries := func() []map[string]interface{} {
+		contents, err := ioutil.ReadFile(logFile.Name())
+		Expect(err).To(BeNil())
+
+		decoded := make([]map[string]interface{}, 0)
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+
+		for {
+			var entry map[string]interface{}
+			if err := decoder.Decode(&entry); err == io.EOF {
+				break
+			} else {
+				Expect(err).To(BeNil())
+			}
+
+			if entry["msg"] == "Request completed" {
+				decoded = append(decoded, entry)
+			}
+		}
+
+		return decoded
+	}
+
+	perform := func(path string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(api.RequestIDHeader, "log-1")
+
+		router.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	newRouter := func(accessLog config.AccessLog) {
+		router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api", AccessLog: accessLog})
+
+		controller := api.NewController("/things")
+		controller.Register(api.NewGETRoute("/:id", func(c *gin.Context) {
+			c.String(http.StatusOK, "thing")
+		}))
+		controller.Register(api.NewGETRoute("/:id/broken", func(c *gin.Context) {
+			api.RenderError(c, api.ErrInternal())
+		}))
+		router.Register(controller)
+
+		heartbeat := api.NewController("/heartbeat")
+		heartbeat.Register(api.NewGETRoute("/liveness", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		}))
+		router.Register(heartbeat)
+	}
+
+	BeforeEach(func() {
+		var err error
+		logFile, err = ioutil.TempFile("", "agora-access-log")
+		Expect(err).To(BeNil())
+
+		logg.Use(config.Logging{Level: "info", OutputPaths: []string{logFile.Name()}})
+	})
+
+	AfterEach(func() {
+		logg.Use(config.Logging{Level: "trace", OutputPaths: []string{"stdout"}})
+		_ = os.Remove(logFile.Name())
+	})
+
+	It("should log requests through logg with the route template", func() {
+		newRouter(config.AccessLog{Enabled: true, SampleRate: 1})
+
+		perform("/api/things/42")
+
+		logged := entries()
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]["method"]).To(Equal(http.MethodGet))
+		Expect(logged[0]["route"]).To(Equal("/api/things/:id"))
+		Expect(logged[0]["path"]).To(Equal("/api/things/42"))
+		Expect(logged[0]["status"]).To(BeNumerically("==", http.StatusOK))
+		Expect(logged[0]["bytes"]).To(BeNumerically("==", 5))
+		Expect(logged[0]["client_ip"]).To(Equal("192.0.2.1"))
+		Expect(logged[0][logg.RequestIDField]).To(Equal("log-1"))
+		Expect(logged[0]).To(HaveKey("latency_ms"))
+	})
+
+	It("should skip excluded paths", func() {
+		newRouter(config.AccessLog{Enabled: true, SampleRate: 1, Exclude: []string{"/heartbeat/*"}})
+
+		perform("/api/heartbeat/liveness")
+		perform("/api/things/42")
+
+		logged := entries()
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]["route"]).To(Equal("/api/things/:id"))
+	})
+
+	It("should always log server errors regardless of sampling", func() {
+		newRouter(config.AccessLog{Enabled: true, SampleRate: 0})
+
+		perform("/api/things/42")
+		perform("/api/things/42/broken")
+
+		logged := entries()
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0]["status"]).To(BeNumerically("==", http.StatusInternalServerError))
+		Expect(logged[0]["level"]).To(Equal("error"))
+	})
+
+	It("should not log when disabled", func() {
+		newRouter(config.AccessLog{})
+
+		perform("/api/things/42")
+
+		Expect(entries()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,37 @@
+package api
+
+import (
+  "errors"
+  "sync"
+
+  "github.com/gin-gonic/gin"
+)
+
+var (
+  ErrAuthenticatorRequired = errors.New("route requires auth but no authenticator was provided to `Router.UseAuthenticator`")
+)
+
+type authenticator struct {
+  mu      sync.RWMutex
+  handler func(c *gin.Context)
+}
+
+func (r *Router) UseAuthenticator(handler func(c *gin.Context)) {
+  r.authenticator.mu.Lock()
+  defer r.authenticator.mu.Unlock()
+
+  r.authenticator.handler = handler
+}
+
+func (a *authenticator) middleware(c *gin.Context) {
+  a.mu.RLock()
+  handler := a.handler
+  a.mu.RUnlock()
+
+  if handler == nil {
+    RenderError(c, ErrInternal().WithCause(ErrAuthenticatorRequired))
+    return
+  }
+
+  handler(c)
+}
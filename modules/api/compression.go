@@ -0,0 +1,242 @@
+package api
+
+import (
+  "bytes"
+  "compress/gzip"
+  "io"
+  "mime"
+  "net/http"
+  "strconv"
+  "strings"
+
+  "github.com/andybalholm/brotli"
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  contentEncodingHeader = "Content-Encoding"
+  acceptEncodingHeader  = "Accept-Encoding"
+  varyHeader            = "Vary"
+)
+
+type flushWriter interface {
+  io.WriteCloser
+  Flush() error
+}
+
+func CompressionMiddleware(conf config.Compression) gin.HandlerFunc {
+  allowed := make(map[string]bool, len(conf.ContentTypes))
+  for _, contentType := range conf.ContentTypes {
+    allowed[strings.ToLower(contentType)] = true
+  }
+
+  return func(c *gin.Context) {
+    algorithm := negotiateEncoding(c.GetHeader(acceptEncodingHeader), conf.Algorithms)
+    if algorithm == config.CompressionUnknown || c.Request.Method == http.MethodHead {
+      c.Next()
+      return
+    }
+
+    writer := &compressWriter{
+      ResponseWriter: c.Writer,
+      conf:           conf,
+      algorithm:      algorithm,
+      allowed:        allowed,
+    }
+
+    c.Writer = writer
+    defer func() {
+      writer.finish()
+      c.Writer = writer.ResponseWriter
+    }()
+
+    c.Next()
+  }
+}
+
+func negotiateEncoding(header string, algorithms []config.CompressionAlgorithm) config.CompressionAlgorithm {
+  if header == "" {
+    return config.CompressionUnknown
+  }
+
+  accepted := make(map[string]float64)
+  for _, part := range strings.Split(header, ",") {
+    fields := strings.Split(part, ";")
+    name := strings.ToLower(strings.TrimSpace(fields[0]))
+    quality := 1.0
+
+    for _, param := range fields[1:] {
+      param = strings.TrimSpace(param)
+      if strings.HasPrefix(param, "q=") {
+        if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+          quality = q
+        }
+      }
+    }
+
+    accepted[name] = quality
+  }
+
+  for _, algorithm := range algorithms {
+    if q, ok := accepted[algorithm.String()]; ok {
+      if q > 0 {
+        return algorithm
+      }
+
+      continue
+    }
+
+    if q, ok := accepted["*"]; ok && q > 0 {
+      return algorithm
+    }
+  }
+
+  return config.CompressionUnknown
+}
+
+type compressWriter struct {
+  gin.ResponseWriter
+  conf      config.Compression
+  algorithm config.CompressionAlgorithm
+  allowed   map[string]bool
+  buf       bytes.Buffer
+  encoder   flushWriter
+  decided   bool
+  headerNow bool
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+  w.headerNow = true
+}
+
+func (w *compressWriter) Written() bool {
+  return w.headerNow || w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+  if w.decided {
+    return w.write(data)
+  }
+
+  w.buf.Write(data)
+  if w.buf.Len() >= w.conf.MinSize {
+    if err := w.decide(); err != nil {
+      return 0, err
+    }
+  }
+
+  return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+  return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+  if !w.decided {
+    _ = w.decide()
+  }
+
+  if w.encoder != nil {
+    _ = w.encoder.Flush()
+  }
+
+  w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) write(data []byte) (int, error) {
+  if w.encoder != nil {
+    return w.encoder.Write(data)
+  }
+
+  return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) decide() error {
+  w.decided = true
+
+  if w.shouldCompress() {
+    header := w.Header()
+    header.Set(contentEncodingHeader, w.algorithm.String())
+    header.Del("Content-Length")
+    w.encoder = w.newEncoder()
+  }
+
+  if w.buf.Len() == 0 {
+    return nil
+  }
+
+  _, err := w.write(w.buf.Bytes())
+  w.buf.Reset()
+
+  return err
+}
+
+func (w *compressWriter) finish() {
+  if !w.decided {
+    _ = w.decide()
+  }
+
+  if w.encoder != nil {
+    _ = w.encoder.Close()
+  }
+
+  if w.headerNow && !w.ResponseWriter.Written() {
+    w.ResponseWriter.WriteHeaderNow()
+  }
+}
+
+func (w *compressWriter) shouldCompress() bool {
+  header := w.Header()
+
+  if !w.isCompressibleType(header.Get("Content-Type")) {
+    return false
+  }
+
+  header.Add(varyHeader, acceptEncodingHeader)
+
+  if header.Get(contentEncodingHeader) != "" {
+    return false
+  }
+
+  switch w.Status() {
+  case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+    return false
+  }
+
+  return w.buf.Len() >= w.conf.MinSize
+}
+
+func (w *compressWriter) isCompressibleType(contentType string) bool {
+  if contentType == "" {
+    return false
+  }
+
+  mediaType, _, err := mime.ParseMediaType(contentType)
+  if err != nil {
+    return false
+  }
+
+  return w.allowed[strings.ToLower(mediaType)]
+}
+
+func (w *compressWriter) newEncoder() flushWriter {
+  level := w.conf.Level
+
+  if w.algorithm == config.CompressionBrotli {
+    if level <= 0 || level > brotli.BestCompression {
+      level = brotli.DefaultCompression
+    }
+
+    return brotli.NewWriterLevel(w.ResponseWriter, level)
+  }
+
+  if level == 0 || level < gzip.HuffmanOnly || level > gzip.BestCompression {
+    level = gzip.DefaultCompression
+  }
+
+  encoder, _ := gzip.NewWriterLevel(w.ResponseWriter, level)
+
+  return encoder
+}
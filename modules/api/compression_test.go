@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Compression", func() {
+	var (
+		router api.Router
+		large  = strings.Repeat("agora ", 512)
+	)
+
+	perform := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	BeforeEach(func() {
+		router = api.NewRouter(config.API{
+			Port:       8123,
+			PathPrefix: "/api",
+			Compression: config.Compression{
+				Enabled:      true,
+				Algorithms:   []config.CompressionAlgorithm{config.CompressionBrotli, config.CompressionGzip},
+				MinSize:      1024,
+				ContentTypes: []string{"application/json", "text/plain"},
+			},
+		})
+
+		controller := api.NewController("/payload")
+		controller.Register(api.NewGETRoute("/large", func(c *gin.Context) {
+			c.JSON(http.StatusOK, map[string]string{"data": large})
+		}))
+		controller.Register(api.NewGETRoute("/small", func(c *gin.Context) {
+			c.JSON(http.StatusOK, map[string]string{"data": "tiny"})
+		}))
+		controller.Register(api.NewGETRoute("/binary", func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/octet-stream", []byte(large))
+		}))
+		controller.Register(api.NewGETRoute("/empty", func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		}))
+		router.Register(controller)
+	})
+
+	It("should gzip large responses when requested", func() {
+		rr := perform("/api/payload/large", "gzip")
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(rr.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+
+		reader, err := gzip.NewReader(rr.Body)
+		Expect(err).To(BeNil())
+
+		body, err := ioutil.ReadAll(reader)
+		Expect(err).To(BeNil())
+		Expect(string(body)).To(ContainSubstring(large))
+	})
+
+	It("should prefer brotli according to the configured order", func() {
+		rr := perform("/api/payload/large", "gzip, br;q=0.8")
+
+		Expect(rr.Header().Get("Content-Encoding")).To(Equal("br"))
+
+		body, err := ioutil.ReadAll(brotli.NewReader(rr.Body))
+		Expect(err).To(BeNil())
+		Expect(string(body)).To(ContainSubstring(large))
+	})
+
+	It("should honour encodings the client refuses", func() {
+		rr := perform("/api/payload/large", "br;q=0, gzip")
+
+		Expect(rr.Header().Get("Content-Encoding")).To(Equal("gzip"))
+	})
+
+	It("should leave responses uncompressed when not negotiated", func() {
+		rr := perform("/api/payload/large", "")
+
+		Expect(rr.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(rr.Body.String()).To(ContainSubstring(large))
+	})
+
+	It("should leave responses below the minimum size uncompressed", func() {
+		rr := perform("/api/payload/small", "gzip")
+
+		Expect(rr.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(rr.Header().Get("Vary")).To(Equal("Accept-Encoding"))
+		Expect(rr.Body.String()).To(Equal(`{"data":"tiny"}`))
+	})
+
+	It("should leave content types outside the allowlist uncompressed", func() {
+		rr := perform("/api/payload/binary", "gzip")
+
+		Expect(rr.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(rr.Body.String()).To(Equal(large))
+	})
+
+	It("should still write bodiless responses", func() {
+		rr := perform("/api/payload/empty", "gzip")
+
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+		Expect(rr.Body.Len()).To(BeZero())
+	})
+
+	It("should compress problem responses", func() {
+		rr := perform("/api/payload/missing", "gzip")
+
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+		Expect(rr.Body.String()).To(ContainSubstring("not_found"))
+	})
+})
@@ -0,0 +1,229 @@
+package api
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "net/http"
+  "strconv"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+)
+
+type FieldError struct {
+  Field   string `json:"field"`
+  Message string `json:"message"`
+}
+
+var (
+  ProblemContentType = "application/problem+json"
+  defaultProblemType = "about:blank"
+)
+
+type Error struct {
+  Type     string       `json:"type"`
+  Title    string       `json:"title"`
+  Status   int          `json:"status"`
+  Code     string       `json:"code"`
+  Detail   string       `json:"detail,omitempty"`
+  Instance string       `json:"instance,omitempty"`
+  Fields   []FieldError `json:"fields,omitempty"`
+  cause    error
+}
+
+func (e *Error) Error() string {
+  if e.Detail != "" {
+    return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+  }
+
+  return e.Code
+}
+
+func (e *Error) Unwrap() error {
+  return e.cause
+}
+
+func (e *Error) WithCause(err error) *Error {
+  return &Error{
+    Type:     e.Type,
+    Title:    e.Title,
+    Status:   e.Status,
+    Code:     e.Code,
+    Detail:   e.Detail,
+    Instance: e.Instance,
+    Fields:   e.Fields,
+    cause:    err,
+  }
+}
+
+func NewError(status int, code, detail string) *Error {
+  return &Error{
+    Type:   defaultProblemType,
+    Title:  http.StatusText(status),
+    Status: status,
+    Code:   code,
+    Detail: detail,
+  }
+}
+
+func ErrBadRequest(detail string) *Error {
+  return NewError(http.StatusBadRequest, "bad_request", detail)
+}
+
+func ErrUnauthorized(detail string) *Error {
+  return NewError(http.StatusUnauthorized, "unauthorized", detail)
+}
+
+func ErrForbidden(detail string) *Error {
+  return NewError(http.StatusForbidden, "forbidden", detail)
+}
+
+func ErrNotFound(detail string) *Error {
+  return NewError(http.StatusNotFound, "not_found", detail)
+}
+
+func ErrMethodNotAllowed(detail string) *Error {
+  return NewError(http.StatusMethodNotAllowed, "method_not_allowed", detail)
+}
+
+func ErrConflict(detail string) *Error {
+  return NewError(http.StatusConflict, "conflict", detail)
+}
+
+func ErrTooManyRequests(detail string) *Error {
+  return NewError(http.StatusTooManyRequests, "rate_limited", detail)
+}
+
+func ErrPayloadTooLarge(detail string) *Error {
+  return NewError(http.StatusRequestEntityTooLarge, "payload_too_large", detail)
+}
+
+func ErrServiceUnavailable(detail string) *Error {
+  return NewError(http.StatusServiceUnavailable, "service_unavailable", detail)
+}
+
+func ErrBadGateway(detail string) *Error {
+  return NewError(http.StatusBadGateway, "bad_gateway", detail)
+}
+
+func ErrGatewayTimeout(detail string) *Error {
+  return NewError(http.StatusGatewayTimeout, "gateway_timeout", detail)
+}
+
+func ErrPreconditionFailed(detail string) *Error {
+  return NewError(http.StatusPreconditionFailed, "precondition_failed", detail)
+}
+
+func ErrPreconditionRequired(detail string) *Error {
+  return NewError(http.StatusPreconditionRequired, "precondition_required", detail)
+}
+
+func ErrValidation(fields []FieldError) *Error {
+  err := NewError(http.StatusUnprocessableEntity, "validation_failed", "request failed validation")
+  err.Fields = fields
+
+  return err
+}
+
+func ErrInternal() *Error {
+  return NewError(http.StatusInternalServerError, "internal_error", http.StatusText(http.StatusInternalServerError))
+}
+
+func AsError(err error) *Error {
+  var apiErr *Error
+  if errors.As(err, &apiErr) {
+    return apiErr
+  }
+
+  if errors.Is(err, ErrRequestBodyTooLarge) {
+    return ErrPayloadTooLarge(err.Error()).WithCause(err)
+  }
+
+  if errors.Is(err, context.DeadlineExceeded) {
+    return ErrGatewayTimeout("upstream dependency did not respond in time").WithCause(err)
+  }
+
+  return ErrInternal().WithCause(err)
+}
+
+func RenderError(c *gin.Context, err error) {
+  _ = c.Error(err)
+
+  renderProblem(c, AsError(err))
+}
+
+func renderProblem(c *gin.Context, apiErr *Error) {
+  problem := *apiErr
+  if problem.Instance == "" {
+    problem.Instance = c.Request.URL.Path
+  }
+
+  c.Header("Content-Type", ProblemContentType)
+  c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+func writeProblem(w http.ResponseWriter, req *http.Request, apiErr *Error) {
+  problem := *apiErr
+  if problem.Instance == "" {
+    problem.Instance = req.URL.Path
+  }
+
+  body, _ := json.Marshal(problem)
+
+  w.Header().Set("Content-Type", ProblemContentType)
+  w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+  w.WriteHeader(problem.Status)
+  _, _ = w.Write(body)
+}
+
+func ErrorHandler(c *gin.Context) {
+  c.Next()
+
+  if c.Writer.Written() || len(c.Errors) == 0 {
+    return
+  }
+
+  renderProblem(c, AsError(c.Errors.Last().Err))
+}
+
+func Recovery(c *gin.Context) {
+  defer func() {
+    if r := recover(); r != nil {
+      err, isErr := r.(error)
+      if !isErr {
+        err = fmt.Errorf("%v", r)
+      }
+
+      logg.Root().
+        WithContext(c.Request.Context()).
+        WithField("method", c.Request.Method).
+        WithField("path", c.Request.URL.Path).
+        WithError(err).
+        Error("Recovered from panic")
+
+      if c.Writer.Written() {
+        c.Abort()
+
+        return
+      }
+
+      RenderError(c, ErrInternal().WithCause(err))
+    }
+  }()
+
+  c.Next()
+}
+
+func (r *Router) noRoute(c *gin.Context) {
+  if r.static.serve(c) {
+    return
+  }
+
+  renderProblem(c, ErrNotFound(fmt.Sprintf("no route matches `%s %s`", c.Request.Method, c.Request.URL.Path)))
+}
+
+func noMethodHandler(c *gin.Context) {
+  renderProblem(c, ErrMethodNotAllowed(fmt.Sprintf("method `%s` is not allowed for `%s`", c.Request.Method, c.Request.URL.Path)))
+}
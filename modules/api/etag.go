@@ -0,0 +1,124 @@
+package api
+
+import (
+  "crypto/sha256"
+  "encoding/hex"
+  "encoding/json"
+  "net/http"
+  "strings"
+
+  "github.com/gin-gonic/gin"
+)
+
+var (
+  ETagHeader        = "ETag"
+  ifMatchHeader     = "If-Match"
+  ifNoneMatchHeader = "If-None-Match"
+)
+
+func ETag(v interface{}) (string, error) {
+  var data []byte
+
+  switch value := v.(type) {
+  case []byte:
+    data = value
+  case string:
+    data = []byte(value)
+  default:
+    encoded, err := json.Marshal(v)
+    if err != nil {
+      return "", err
+    }
+
+    data = encoded
+  }
+
+  sum := sha256.Sum256(data)
+
+  return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+func WeakETag(etag string) string {
+  if strings.HasPrefix(etag, "W/") {
+    return etag
+  }
+
+  return "W/" + etag
+}
+
+func CheckPreconditions(c *gin.Context, etag string) bool {
+  safe := isSafeMethod(c.Request.Method)
+
+  if ifMatch := c.GetHeader(ifMatchHeader); ifMatch != "" && !matchesETag(ifMatch, etag, false) {
+    renderProblem(c, ErrPreconditionFailed("resource has been modified"))
+    return false
+  }
+
+  if ifNoneMatch := c.GetHeader(ifNoneMatchHeader); ifNoneMatch != "" && matchesETag(ifNoneMatch, etag, true) {
+    if safe {
+      c.Header(ETagHeader, etag)
+      c.AbortWithStatus(http.StatusNotModified)
+    } else {
+      renderProblem(c, ErrPreconditionFailed("resource already exists"))
+    }
+
+    return false
+  }
+
+  if safe && etag != "" {
+    c.Header(ETagHeader, etag)
+  }
+
+  return true
+}
+
+func RequireIfMatch(c *gin.Context, etag string) bool {
+  if c.GetHeader(ifMatchHeader) == "" {
+    renderProblem(c, ErrPreconditionRequired("`If-Match` header is required"))
+    return false
+  }
+
+  return CheckPreconditions(c, etag)
+}
+
+func JSONWithETag(c *gin.Context, status int, obj interface{}) {
+  etag, err := ETag(obj)
+  if err != nil {
+    RenderError(c, err)
+    return
+  }
+
+  if isSafeMethod(c.Request.Method) && !CheckPreconditions(c, etag) {
+    return
+  }
+
+  c.Header(ETagHeader, etag)
+  c.JSON(status, obj)
+}
+
+func matchesETag(header, etag string, weak bool) bool {
+  if etag == "" {
+    return false
+  }
+
+  for _, candidate := range strings.Split(header, ",") {
+    candidate = strings.TrimSpace(candidate)
+    if candidate == "*" {
+      return true
+    }
+
+    if weak {
+      if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+        return true
+      }
+    } else if !strings.HasPrefix(candidate, "W/") && !strings.HasPrefix(etag, "W/") && candidate == etag {
+      return true
+    }
+  }
+
+  return false
+}
+
+func isSafeMethod(method string) bool {
+  return method == http.MethodGet || method == http.MethodHead
+}
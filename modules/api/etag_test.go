@@ -0,0 +1,117 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type taggedWidget struct {
+	Name string `json:"name" validate:"required"`
+}
+
+var _ = Describe("ETags", func() {
+	var (
+		router  api.Router
+		current taggedWidget
+	)
+
+	perform := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"name":"cog"}`))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	BeforeEach(func() {
+		current = taggedWidget{Name: "sprocket"}
+		router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+
+		controller := api.NewController("/widgets")
+		controller.Register(api.NewGETRoute("/1", func(c *gin.Context) {
+			api.JSONWithETag(c, http.StatusOK, current)
+		}))
+		controller.Register(api.NewPUTRoute("/1", func(c *gin.Context) {
+			etag, err := api.ETag(current)
+			Expect(err).To(BeNil())
+
+			if !api.RequireIfMatch(c, etag) {
+				return
+			}
+
+			var next taggedWidget
+			if err := api.BindJSON(c, &next); err != nil {
+				api.RenderError(c, err)
+				return
+			}
+
+			current = next
+			api.JSONWithETag(c, http.StatusOK, current)
+		}))
+		router.Register(controller)
+	})
+
+	It("should produce stable, quoted ETags", func() {
+		first, err := api.ETag(map[string]string{"name": "sprocket"})
+		Expect(err).To(BeNil())
+
+		second, err := api.ETag(map[string]string{"name": "sprocket"})
+		Expect(err).To(BeNil())
+
+		Expect(first).To(Equal(second))
+		Expect(first).To(HavePrefix(`"`))
+		Expect(first).To(HaveSuffix(`"`))
+		Expect(api.WeakETag(first)).To(Equal("W/" + first))
+	})
+
+	It("should return 304 when If-None-Match matches", func() {
+		etag := perform(http.MethodGet, "/api/widgets/1", nil).Header().Get(api.ETagHeader)
+		Expect(etag).ToNot(BeEmpty())
+
+		rr := perform(http.MethodGet, "/api/widgets/1", map[string]string{"If-None-Match": "W/" + etag})
+		Expect(rr.Code).To(Equal(http.StatusNotModified))
+		Expect(rr.Body.Len()).To(BeZero())
+		Expect(rr.Header().Get(api.ETagHeader)).To(Equal(etag))
+
+		rr = perform(http.MethodGet, "/api/widgets/1", map[string]string{"If-None-Match": `"stale"`})
+		Expect(rr.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should require If-Match for optimistic concurrency", func() {
+		rr := perform(http.MethodPut, "/api/widgets/1", nil)
+
+		Expect(rr.Code).To(Equal(http.StatusPreconditionRequired))
+		Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+	})
+
+	It("should return 412 when If-Match is stale", func() {
+		etag := perform(http.MethodGet, "/api/widgets/1", nil).Header().Get(api.ETagHeader)
+
+		updated := perform(http.MethodPut, "/api/widgets/1", map[string]string{"If-Match": etag})
+		Expect(updated.Code).To(Equal(http.StatusOK))
+		Expect(updated.Body.String()).To(Equal(`{"name":"cog"}`))
+
+		stale := perform(http.MethodPut, "/api/widgets/1", map[string]string{"If-Match": etag})
+		Expect(stale.Code).To(Equal(http.StatusPreconditionFailed))
+		Expect(stale.Body.String()).To(ContainSubstring("precondition_failed"))
+	})
+
+	It("should reject weak ETags in If-Match", func() {
+		etag := perform(http.MethodGet, "/api/widgets/1", nil).Header().Get(api.ETagHeader)
+
+		rr := perform(http.MethodPut, "/api/widgets/1", map[string]string{"If-Match": api.WeakETag(etag)})
+		Expect(rr.Code).To(Equal(http.StatusPreconditionFailed))
+	})
+})
@@ -0,0 +1,309 @@
+package api
+
+import (
+  "bytes"
+  "context"
+  "crypto/sha256"
+  "encoding/hex"
+  "errors"
+  "fmt"
+  "io"
+  "io/ioutil"
+  "net/http"
+  "sync"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  ErrIdempotencyKeyInFlight = errors.New("a request with this idempotency key is already in progress")
+  ErrIdempotencyKeyReused   = errors.New("idempotency key was already used for a different request")
+  IdempotentReplayedHeader  = "Idempotent-Replayed"
+  maxIdempotencyKeyLength   = 255
+  volatileResponseHeaders   = []string{"Date", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}
+  encodingResponseHeaders   = []string{contentEncodingHeader, "Content-Length", varyHeader}
+)
+
+type IdempotentResponse struct {
+  Status int         `json:"status"`
+  Header http.Header `json:"header"`
+  Body   []byte      `json:"body"`
+}
+
+type IdempotencyStore interface {
+  Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (*IdempotentResponse, error)
+  Complete(ctx context.Context, key string, response IdempotentResponse, retention time.Duration) error
+  Release(ctx context.Context, key string) error
+}
+
+type MemoryIdempotencyStore struct {
+  mu         sync.Mutex
+  entries    map[string]memoryIdempotencyEntry
+  lastPruned time.Time
+}
+
+type memoryIdempotencyEntry struct {
+  fingerprint string
+  response    *IdempotentResponse
+  expires     time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+  return &MemoryIdempotencyStore{
+    entries:    make(map[string]memoryIdempotencyEntry),
+    lastPruned: time.Now(),
+  }
+}
+
+func (s *MemoryIdempotencyStore) Begin(_ context.Context, key, fingerprint string, lockTimeout time.Duration) (*IdempotentResponse, error) {
+  now := time.Now()
+
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  if now.Sub(s.lastPruned) >= memoryRateLimitPruneEvery {
+    s.prune(now)
+  }
+
+  if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+    if entry.fingerprint != fingerprint {
+      return nil, ErrIdempotencyKeyReused
+    }
+
+    if entry.response == nil {
+      return nil, ErrIdempotencyKeyInFlight
+    }
+
+    return entry.response, nil
+  }
+
+  s.entries[key] = memoryIdempotencyEntry{fingerprint: fingerprint, expires: now.Add(lockTimeout)}
+
+  return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, response IdempotentResponse, retention time.Duration) error {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  entry := s.entries[key]
+  entry.response = &response
+  entry.expires = time.Now().Add(retention)
+  s.entries[key] = entry
+
+  return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  if entry, ok := s.entries[key]; ok && entry.response == nil {
+    delete(s.entries, key)
+  }
+
+  return nil
+}
+
+func (s *MemoryIdempotencyStore) prune(now time.Time) {
+  for key, entry := range s.entries {
+    if !now.Before(entry.expires) {
+      delete(s.entries, key)
+    }
+  }
+
+  s.lastPruned = now
+}
+
+type idempotency struct {
+  mu    sync.RWMutex
+  conf  config.Idempotency
+  store IdempotencyStore
+}
+
+func newIdempotency(conf config.Idempotency) *idempotency {
+  return &idempotency{conf: conf, store: NewMemoryIdempotencyStore()}
+}
+
+func (r *Router) UseIdempotencyStore(store IdempotencyStore) {
+  r.idempotency.mu.Lock()
+  defer r.idempotency.mu.Unlock()
+
+  r.idempotency.store = store
+}
+
+func (i *idempotency) appliesTo(route Route) bool {
+  return i.conf.Enabled && i.conf.AppliesTo(route.method)
+}
+
+func (i *idempotency) middleware(bodyLimit int64) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    i.handle(c, bodyLimit)
+  }
+}
+
+func (i *idempotency) handle(c *gin.Context, bodyLimit int64) {
+  key := c.GetHeader(i.conf.Header)
+  if key == "" {
+    c.Next()
+    return
+  }
+
+  if len(key) > maxIdempotencyKeyLength {
+    RenderError(c, ErrBadRequest(fmt.Sprintf("`%s` must not exceed %d characters", i.conf.Header, maxIdempotencyKeyLength)))
+    return
+  }
+
+  fingerprint, err := requestFingerprint(c, bodyLimit)
+  if err != nil {
+    if !errors.Is(err, ErrRequestBodyTooLarge) {
+      err = ErrBadRequest("unable to read request body").WithCause(err)
+    }
+
+    RenderError(c, err)
+    return
+  }
+
+  i.mu.RLock()
+  store := i.store
+  i.mu.RUnlock()
+
+  ctx := c.Request.Context()
+  scopedKey := fmt.Sprintf("%s|%s", idempotencyPrincipal(c), key)
+
+  stored, err := store.Begin(ctx, scopedKey, fingerprint, i.conf.LockTimeout)
+  switch {
+  case errors.Is(err, ErrIdempotencyKeyInFlight):
+    RenderError(c, ErrConflict(err.Error()).WithCause(err))
+    return
+  case errors.Is(err, ErrIdempotencyKeyReused):
+    RenderError(c, NewError(http.StatusUnprocessableEntity, "idempotency_key_reused", err.Error()).WithCause(err))
+    return
+  case err != nil:
+    logg.Root().WithContext(ctx).WithError(err).Warn("Idempotency store unavailable, processing request")
+    c.Next()
+    return
+  case stored != nil:
+    replay(c, *stored)
+    return
+  }
+
+  writer := &recordingWriter{ResponseWriter: c.Writer}
+  c.Writer = writer
+
+  completed := false
+  defer func() {
+    c.Writer = writer.ResponseWriter
+
+    if !completed {
+      if err := store.Release(context.Background(), scopedKey); err != nil {
+        logg.Root().WithContext(ctx).WithError(err).Warn("Failed to release idempotency key")
+      }
+    }
+  }()
+
+  c.Next()
+
+  if writer.Status() >= http.StatusInternalServerError {
+    return
+  }
+
+  response := IdempotentResponse{
+    Status: writer.Status(),
+    Header: storableHeader(writer.Header()),
+    Body:   writer.body.Bytes(),
+  }
+
+  if err := store.Complete(context.Background(), scopedKey, response, i.conf.Retention); err != nil {
+    logg.Root().WithContext(ctx).WithError(err).Warn("Failed to store idempotent response")
+    return
+  }
+
+  completed = true
+}
+
+func idempotencyPrincipal(c *gin.Context) string {
+  if subject := c.GetString("subject"); subject != "" {
+    return "subject:" + subject
+  }
+
+  return "anonymous"
+}
+
+func requestFingerprint(c *gin.Context, bodyLimit int64) (string, error) {
+  hash := sha256.New()
+  fmt.Fprintf(hash, "%s %s\n", c.Request.Method, c.Request.URL.RequestURI())
+
+  if c.Request.Body != nil {
+    var reader io.Reader = c.Request.Body
+    if bodyLimit > 0 {
+      reader = io.LimitReader(reader, bodyLimit+1)
+    }
+
+    body, err := ioutil.ReadAll(reader)
+    if err != nil {
+      return "", err
+    }
+
+    if bodyLimit > 0 && int64(len(body)) > bodyLimit {
+      return "", ErrRequestBodyTooLarge
+    }
+
+    c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+    hash.Write(body)
+  }
+
+  return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func storableHeader(header http.Header) http.Header {
+  stored := header.Clone()
+  stored.Del(RequestIDHeader)
+
+  for _, name := range volatileResponseHeaders {
+    stored.Del(name)
+  }
+
+  for _, name := range encodingResponseHeaders {
+    stored.Del(name)
+  }
+
+  return stored
+}
+
+func replay(c *gin.Context, response IdempotentResponse) {
+  for name, values := range response.Header {
+    c.Writer.Header()[name] = values
+  }
+
+  c.Header(IdempotentReplayedHeader, "true")
+  c.Status(response.Status)
+
+  if len(response.Body) > 0 {
+    _, _ = c.Writer.Write(response.Body)
+  } else {
+    c.Writer.WriteHeaderNow()
+  }
+
+  c.Abort()
+}
+
+type recordingWriter struct {
+  gin.ResponseWriter
+  body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+  w.body.Write(data)
+
+  return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+  w.body.WriteString(s)
+
+  return w.ResponseWriter.WriteString(s)
+}
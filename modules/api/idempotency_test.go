@@ -0,0 +1,207 @@
+package api_test
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Idempotency", func() {
+	var (
+		router  api.Router
+		created int32
+		started chan struct{}
+		release chan struct{}
+	)
+
+	perform := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&created, 0)
+		started = make(chan struct{})
+		release = make(chan struct{})
+
+		router = api.NewRouter(config.API{
+			Port:       8123,
+			PathPrefix: "/api",
+			Idempotency: config.Idempotency{
+				Enabled:     true,
+				Header:      "Idempotency-Key",
+				Methods:     []string{http.MethodPost},
+				Retention:   time.Hour,
+				LockTimeout: time.Minute,
+			},
+		})
+
+		controller := api.NewController("/orders")
+		controller.Register(api.NewPOSTRoute("", func(c *gin.Context) {
+			id := atomic.AddInt32(&created, 1)
+			c.Header("Location", "/api/orders/1")
+			c.JSON(http.StatusCreated, map[string]int32{"id": id})
+		}))
+		controller.Register(api.NewPOSTRoute("/slow", func(c *gin.Context) {
+			close(started)
+			<-release
+			c.JSON(http.StatusCreated, map[string]int32{"id": atomic.AddInt32(&created, 1)})
+		}))
+		controller.Register(api.NewPOSTRoute("/small", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, map[string]int32{"id": atomic.AddInt32(&created, 1)})
+		}).WithMaxBodySize(8))
+		controller.Register(api.NewPOSTRoute("/broken", func(c *gin.Context) {
+			atomic.AddInt32(&created, 1)
+			api.RenderError(c, api.ErrInternal())
+		}))
+		router.Register(controller)
+	})
+
+	It("should replay the first response for retries", func() {
+		first := perform("/api/orders", "key-1", `{"item":"cog"}`)
+		Expect(first.Code).To(Equal(http.StatusCreated))
+		Expect(first.Header().Get(api.IdempotentReplayedHeader)).To(BeEmpty())
+
+		retry := perform("/api/orders", "key-1", `{"item":"cog"}`)
+		Expect(retry.Code).To(Equal(http.StatusCreated))
+		Expect(retry.Body.String()).To(Equal(first.Body.String()))
+		Expect(retry.Header().Get("Location")).To(Equal("/api/orders/1"))
+		Expect(retry.Header().Get(api.IdempotentReplayedHeader)).To(Equal("true"))
+		Expect(atomic.LoadInt32(&created)).To(Equal(int32(1)))
+	})
+
+	It("should reject bodies over the route limit with 413", func() {
+		req := httptest.NewRequest(http.MethodPost, "/api/orders/small", strings.NewReader(`{"item":"sprocket"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "key-large")
+		req.ContentLength = -1
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		Expect(rr.Code).To(Equal(http.StatusRequestEntityTooLarge))
+		Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+		Expect(atomic.LoadInt32(&created)).To(Equal(int32(0)))
+	})
+
+	It("should process requests without a key every time", func() {
+		perform("/api/orders", "", `{}`)
+		perform("/api/orders", "", `{}`)
+
+		Expect(atomic.LoadInt32(&created)).To(Equal(int32(2)))
+	})
+
+	It("should reject a key reused for a different request", func() {
+		Expect(perform("/api/orders", "key-2", `{"item":"cog"}`).Code).To(Equal(http.StatusCreated))
+
+		rr := perform("/api/orders", "key-2", `{"item":"sprocket"}`)
+		Expect(rr.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(rr.Body.String()).To(ContainSubstring("idempotency_key_reused"))
+	})
+
+	It("should return 409 for concurrent duplicates", func() {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() {
+			done <- perform("/api/orders/slow", "key-3", `{}`)
+		}()
+
+		<-started
+		Expect(perform("/api/orders/slow", "key-3", `{}`).Code).To(Equal(http.StatusConflict))
+
+		close(release)
+		Expect((<-done).Code).To(Equal(http.StatusCreated))
+	})
+
+	It("should not store server errors", func() {
+		Expect(perform("/api/orders/broken", "key-4", `{}`).Code).To(Equal(http.StatusInternalServerError))
+		Expect(perform("/api/orders/broken", "key-4", `{}`).Code).To(Equal(http.StatusInternalServerError))
+
+		Expect(atomic.LoadInt32(&created)).To(Equal(int32(2)))
+	})
+
+	It("should replay compressed responses with the negotiated encoding", func() {
+		router = api.NewRouter(config.API{
+			Port:       8123,
+			PathPrefix: "/api",
+			Compression: config.Compression{
+				Enabled:      true,
+				Algorithms:   []config.CompressionAlgorithm{config.CompressionGzip},
+				ContentTypes: []string{"application/json"},
+			},
+			Idempotency: config.Idempotency{
+				Enabled:     true,
+				Header:      "Idempotency-Key",
+				Methods:     []string{http.MethodPost},
+				Retention:   time.Hour,
+				LockTimeout: time.Minute,
+			},
+		})
+
+		controller := api.NewController("/orders")
+		controller.Register(api.NewPOSTRoute("", func(c *gin.Context) {
+			c.JSON(http.StatusCreated, map[string]int32{"id": atomic.AddInt32(&created, 1)})
+		}))
+		router.Register(controller)
+
+		performEncoded := func(acceptEncoding string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(`{"item":"cog"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Idempotency-Key", "key-5")
+			if acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", acceptEncoding)
+			}
+
+			rr := httptest.NewRecorder()
+			router.Handler().ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		decode := func(rr *httptest.ResponseRecorder) string {
+			reader, err := gzip.NewReader(rr.Body)
+			Expect(err).To(BeNil())
+
+			body, err := ioutil.ReadAll(reader)
+			Expect(err).To(BeNil())
+
+			return string(body)
+		}
+
+		first := performEncoded("gzip")
+		Expect(first.Code).To(Equal(http.StatusCreated))
+		Expect(first.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		expected := decode(first)
+
+		plain := performEncoded("")
+		Expect(plain.Code).To(Equal(http.StatusCreated))
+		Expect(plain.Header().Get(api.IdempotentReplayedHeader)).To(Equal("true"))
+		Expect(plain.Header().Get("Content-Encoding")).To(BeEmpty())
+		Expect(plain.Body.String()).To(Equal(expected))
+
+		compressed := performEncoded("gzip")
+		Expect(compressed.Code).To(Equal(http.StatusCreated))
+		Expect(compressed.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		Expect(compressed.Header()["Vary"]).To(Equal([]string{"Accept-Encoding"}))
+		Expect(decode(compressed)).To(Equal(expected))
+
+		Expect(atomic.LoadInt32(&created)).To(Equal(int32(1)))
+	})
+})
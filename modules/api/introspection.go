@@ -0,0 +1,124 @@
+package api
+
+import (
+  "net/http"
+  "reflect"
+  "runtime"
+  "strings"
+  "sync"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/types/config"
+)
+
+type RouteInfo struct {
+  Method     string   `json:"method"`
+  Path       string   `json:"path"`
+  Controller string   `json:"controller"`
+  Handler    string   `json:"handler"`
+  Middleware []string `json:"middleware"`
+  Secured    bool     `json:"secured"`
+  Version    string   `json:"version,omitempty"`
+  Deprecated bool     `json:"deprecated"`
+}
+
+type registeredRoute struct {
+  path       string
+  version    config.APIVersion
+  controller string
+  route      Route
+  secured    bool
+  handler    string
+  middleware []string
+}
+
+func (r registeredRoute) info() RouteInfo {
+  middleware := make([]string, len(r.middleware))
+  copy(middleware, r.middleware)
+
+  return RouteInfo{
+    Method:     r.route.method,
+    Path:       r.path,
+    Controller: r.controller,
+    Handler:    r.handler,
+    Middleware: middleware,
+    Secured:    r.secured,
+    Version:    r.version.Name,
+    Deprecated: r.version.IsDeprecated(),
+  }
+}
+
+type routeRegistry struct {
+  mu     sync.RWMutex
+  routes []registeredRoute
+}
+
+func (r *routeRegistry) add(route registeredRoute) {
+  r.mu.Lock()
+  defer r.mu.Unlock()
+
+  r.routes = append(r.routes, route)
+}
+
+func (r *routeRegistry) all() []registeredRoute {
+  r.mu.RLock()
+  defer r.mu.RUnlock()
+
+  routes := make([]registeredRoute, len(r.routes))
+  copy(routes, r.routes)
+
+  return routes
+}
+
+func (r *Router) Routes() []RouteInfo {
+  return r.routes(allVersions)
+}
+
+func (r *Router) VersionRoutes(version string) []RouteInfo {
+  return r.routes(forVersion(version))
+}
+
+func (r *Router) routes(include func(registeredRoute) bool) []RouteInfo {
+  routes := make([]RouteInfo, 0)
+
+  for _, route := range r.registry.all() {
+    if include(route) {
+      routes = append(routes, route.info())
+    }
+  }
+
+  return routes
+}
+
+func handlerName(handler interface{}) string {
+  fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+  if fn == nil {
+    return "unknown"
+  }
+
+  name := fn.Name()
+  if i := strings.LastIndex(name, "/"); i >= 0 {
+    name = name[i+1:]
+  }
+
+  return name
+}
+
+func handlerNames(handlers gin.HandlersChain) []string {
+  names := make([]string, len(handlers))
+  for i, handler := range handlers {
+    names[i] = handlerName(handler)
+  }
+
+  return names
+}
+
+func routesHandler(router *Router) func(c *gin.Context) {
+  return func(c *gin.Context) {
+    if version := c.Query("version"); version != "" {
+      c.JSON(http.StatusOK, router.VersionRoutes(version))
+    } else {
+      c.JSON(http.StatusOK, router.Routes())
+    }
+  }
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+  "bytes"
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "sync"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+)
+
+var (
+  ErrRequestBodyTooLarge = errors.New("request body too large")
+)
+
+func (r Route) WithTimeout(timeout time.Duration) Route {
+  r.timeout = timeout
+
+  return r
+}
+
+func (r Route) WithMaxBodySize(size int64) Route {
+  r.maxBodySize = size
+
+  return r
+}
+
+func (c *Controller) UseTimeout(timeout time.Duration) {
+  c.timeout = timeout
+}
+
+func (c *Controller) UseMaxBodySize(size int64) {
+  c.maxBodySize = size
+}
+
+func (r *Router) timeoutFor(controller Controller, route Route) time.Duration {
+  return time.Duration(resolveLimit(int64(route.timeout), int64(controller.timeout), int64(r.api.Timeout.Handler), route))
+}
+
+func (r *Router) maxBodySizeFor(controller Controller, route Route) int64 {
+  return resolveLimit(route.maxBodySize, controller.maxBodySize, r.api.MaxBodySize, route)
+}
+
+func resolveLimit(route, controller, global int64, r Route) int64 {
+  if r.stream != nil {
+    return 0
+  }
+
+  for _, limit := range []int64{route, controller, global} {
+    if limit < 0 {
+      return 0
+    }
+
+    if limit > 0 {
+      return limit
+    }
+  }
+
+  return 0
+}
+
+func bodyLimitMiddleware(limit int64) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    if c.Request.ContentLength > limit {
+      c.Header("Connection", "close")
+      RenderError(c, ErrPayloadTooLarge(fmt.Sprintf("request body exceeds the limit of %d bytes", limit)))
+
+      return
+    }
+
+    if c.Request.Body != nil && c.Request.Body != http.NoBody {
+      c.Request.Body = &limitedBody{ReadCloser: c.Request.Body, remaining: limit}
+    }
+
+    c.Next()
+  }
+}
+
+type limitedBody struct {
+  io.ReadCloser
+  remaining int64
+  exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+  if b.exceeded {
+    return 0, ErrRequestBodyTooLarge
+  }
+
+  if int64(len(p)) > b.remaining+1 {
+    p = p[:b.remaining+1]
+  }
+
+  n, err := b.ReadCloser.Read(p)
+  if int64(n) > b.remaining {
+    n, b.remaining, b.exceeded = int(b.remaining), 0, true
+
+    return n, ErrRequestBodyTooLarge
+  }
+
+  b.remaining -= int64(n)
+
+  return n, err
+}
+
+func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+    defer cancel()
+
+    original := c.Writer
+    writer := newTimeoutWriter(original)
+
+    req := c.Request.WithContext(ctx)
+    c.Request = req
+    c.Writer = writer
+
+    done := make(chan interface{}, 1)
+
+    go func() {
+      defer func() {
+        done <- recover()
+      }()
+
+      c.Next()
+    }()
+
+    select {
+    case recovered := <-done:
+      c.Writer = original
+      if recovered != nil {
+        panic(recovered)
+      }
+
+      writer.flushTo(original)
+    case <-ctx.Done():
+      writer.timeout()
+      writeTimeoutProblem(original, req, timeout)
+
+      recovered := <-done
+      c.Writer = original
+      c.Abort()
+
+      if recovered != nil {
+        logg.Root().WithContext(ctx).WithError(fmt.Errorf("%v", recovered)).Error("Recovered from panic after handler timeout")
+      }
+    }
+  }
+}
+
+func writeTimeoutProblem(w gin.ResponseWriter, req *http.Request, timeout time.Duration) {
+  problem := ErrServiceUnavailable(fmt.Sprintf("request did not complete within %s", timeout))
+  problem.Code = "handler_timeout"
+
+  writeProblem(w, req, problem)
+  w.Flush()
+}
+
+type timeoutWriter struct {
+  gin.ResponseWriter
+  mu          sync.Mutex
+  header      http.Header
+  body        bytes.Buffer
+  status      int
+  wroteHeader bool
+  timedOut    bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+  return &timeoutWriter{
+    ResponseWriter: w,
+    header:         w.Header().Clone(),
+    status:         w.Status(),
+  }
+}
+
+func (w *timeoutWriter) Header() http.Header {
+  return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  if code > 0 && !w.wroteHeader {
+    w.status = code
+  }
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  if w.timedOut {
+    return len(data), nil
+  }
+
+  w.wroteHeader = true
+
+  return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+  return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  if !w.wroteHeader {
+    return -1
+  }
+
+  return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  return w.wroteHeader
+}
+
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) timeout() {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  w.timedOut = true
+}
+
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+  w.mu.Lock()
+  defer w.mu.Unlock()
+
+  header := dst.Header()
+  for key := range header {
+    if _, ok := w.header[key]; !ok {
+      header.Del(key)
+    }
+  }
+
+  for key, values := range w.header {
+    header[key] = values
+  }
+
+  dst.WriteHeader(w.status)
+
+  if !w.wroteHeader {
+    return
+  }
+
+  if w.body.Len() > 0 {
+    _, _ = dst.Write(w.body.Bytes())
+
+    return
+  }
+
+  dst.WriteHeaderNow()
+}
@@ -0,0 +1,205 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type limitedPayload struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Request limits", func() {
+	var (
+		router api.Router
+	)
+
+	perform := func(method, path, body string, contentLength int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = contentLength
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	problemCode := func(rr *httptest.ResponseRecorder) string {
+		var problem api.Error
+		Expect(json.Unmarshal(rr.Body.Bytes(), &problem)).To(Succeed())
+
+		return problem.Code
+	}
+
+	Context("when a max body size is configured", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api", MaxBodySize: 16})
+
+			bindHandler := func(c *gin.Context) {
+				var payload limitedPayload
+				if err := api.BindJSON(c, &payload); err != nil {
+					api.RenderError(c, err)
+
+					return
+				}
+
+				c.JSON(http.StatusCreated, payload)
+			}
+
+			rawHandler := func(c *gin.Context) {
+				if _, err := ioutil.ReadAll(c.Request.Body); err != nil {
+					api.RenderError(c, err)
+
+					return
+				}
+
+				c.Status(http.StatusNoContent)
+			}
+
+			controller := api.NewController("/uploads")
+			controller.Register(api.NewPOSTRoute("", bindHandler))
+			controller.Register(api.NewPOSTRoute("/raw", rawHandler))
+			controller.Register(api.NewPOSTRoute("/large", bindHandler).WithMaxBodySize(1024))
+			controller.Register(api.NewPOSTRoute("/unlimited", rawHandler).WithMaxBodySize(-1))
+
+			router.Register(controller)
+		})
+
+		It("should accept bodies within the limit", func() {
+			body := `{"name":"a"}`
+			rr := perform(http.MethodPost, "/api/uploads", body, int64(len(body)))
+
+			Expect(rr.Code).To(Equal(http.StatusCreated))
+			Expect(rr.Body.String()).To(Equal(body))
+		})
+
+		It("should reject declared oversized bodies with 413 before the handler runs", func() {
+			body := `{"name":"a very long name"}`
+			rr := perform(http.MethodPost, "/api/uploads", body, int64(len(body)))
+
+			Expect(rr.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+			Expect(problemCode(rr)).To(Equal("payload_too_large"))
+		})
+
+		It("should reject oversized bodies of unknown length while they are read", func() {
+			body := `{"name":"a very long name"}`
+
+			Expect(perform(http.MethodPost, "/api/uploads", body, -1).Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(perform(http.MethodPost, "/api/uploads/raw", body, -1).Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(perform(http.MethodPost, "/api/uploads/raw", `{"name":"a"}`, -1).Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should honour per-route overrides", func() {
+			body := `{"name":"a very long name"}`
+
+			Expect(perform(http.MethodPost, "/api/uploads/large", body, int64(len(body))).Code).To(Equal(http.StatusCreated))
+			Expect(perform(http.MethodPost, "/api/uploads/unlimited", strings.Repeat("x", 4096), -1).Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Context("when handler timeouts are configured", func() {
+		var (
+			cancelled chan error
+		)
+
+		BeforeEach(func() {
+			cancelled = make(chan error, 1)
+
+			router = api.NewRouter(config.API{
+				Port:       8123,
+				PathPrefix: "/api",
+				Timeout:    config.TimeoutOptions{Handler: time.Second},
+			})
+
+			slowHandler := func(c *gin.Context) {
+				<-c.Request.Context().Done()
+				cancelled <- c.Request.Context().Err()
+
+				c.JSON(http.StatusOK, gin.H{"late": true})
+			}
+
+			fastHandler := func(c *gin.Context) {
+				c.Header("X-Handled", "true")
+				c.JSON(http.StatusAccepted, gin.H{"fast": true})
+			}
+
+			dependencyHandler := func(c *gin.Context) {
+				ctx, cancel := context.WithTimeout(c.Request.Context(), time.Millisecond)
+				defer cancel()
+
+				<-ctx.Done()
+				api.RenderError(c, ctx.Err())
+			}
+
+			panicHandler := func(c *gin.Context) {
+				panic("boom")
+			}
+
+			controller := api.NewController("/jobs")
+			controller.UseTimeout(50 * time.Millisecond)
+			controller.Register(api.NewGETRoute("/slow", slowHandler))
+			controller.Register(api.NewGETRoute("/fast", fastHandler))
+			controller.Register(api.NewGETRoute("/dependency", dependencyHandler))
+			controller.Register(api.NewGETRoute("/panic", panicHandler))
+			controller.Register(api.NewGETRoute("/quick", slowHandler).WithTimeout(10 * time.Millisecond))
+
+			router.Register(controller)
+		})
+
+		It("should cancel the request context and respond with 503", func() {
+			start := time.Now()
+			rr := perform(http.MethodGet, "/api/jobs/slow", "", 0)
+
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(rr.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+			Expect(problemCode(rr)).To(Equal("handler_timeout"))
+			Expect(rr.Body.String()).ToNot(ContainSubstring("late"))
+			Expect(cancelled).To(Receive(Equal(context.DeadlineExceeded)))
+		})
+
+		It("should prefer route timeouts over controller timeouts", func() {
+			rr := perform(http.MethodGet, "/api/jobs/quick", "", 0)
+
+			Expect(rr.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(problemCode(rr)).To(Equal("handler_timeout"))
+			Expect(rr.Body.String()).To(ContainSubstring("10ms"))
+		})
+
+		It("should pass through responses that complete in time", func() {
+			rr := perform(http.MethodGet, "/api/jobs/fast", "", 0)
+
+			Expect(rr.Code).To(Equal(http.StatusAccepted))
+			Expect(rr.Header().Get("X-Handled")).To(Equal("true"))
+			Expect(rr.Header().Get(api.RequestIDHeader)).ToNot(BeEmpty())
+			Expect(rr.Body.String()).To(Equal(`{"fast":true}`))
+		})
+
+		It("should map exceeded dependency deadlines to 504", func() {
+			rr := perform(http.MethodGet, "/api/jobs/dependency", "", 0)
+
+			Expect(rr.Code).To(Equal(http.StatusGatewayTimeout))
+			Expect(problemCode(rr)).To(Equal("gateway_timeout"))
+		})
+
+		It("should recover from panics raised inside the timeout", func() {
+			rr := perform(http.MethodGet, "/api/jobs/panic", "", 0)
+
+			Expect(rr.Code).To(Equal(http.StatusInternalServerError))
+			Expect(problemCode(rr)).To(Equal("internal_error"))
+		})
+	})
+})
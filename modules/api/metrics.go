@@ -0,0 +1,96 @@
+package api
+
+import (
+  "fmt"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/prometheus/client_golang/prometheus"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  httpMetricsComponent = "http"
+  httpMetricsLabels    = []string{"method", "route", "status"}
+)
+
+type HTTPMetrics struct {
+  requests      *prometheus.CounterVec
+  duration      *prometheus.HistogramVec
+  inFlight      prometheus.Gauge
+  responseSizes *prometheus.SummaryVec
+}
+
+func newHTTPMetrics(conf config.Metrics) *HTTPMetrics {
+  buckets := conf.Buckets
+  if len(buckets) == 0 {
+    buckets = prometheus.DefBuckets
+  }
+
+  return &HTTPMetrics{
+    requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+      Name: "http_requests_total",
+      Help: "The total number of HTTP requests handled by the API.",
+    }, httpMetricsLabels),
+    duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+      Name:    "http_request_duration_seconds",
+      Help:    "The latency of HTTP requests handled by the API.",
+      Buckets: buckets,
+    }, httpMetricsLabels),
+    inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+      Name: "http_requests_in_flight",
+      Help: "The number of HTTP requests currently being handled by the API.",
+    }),
+    responseSizes: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+      Name:       "http_response_size_bytes",
+      Help:       "The size of HTTP responses written by the API.",
+      Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+    }, httpMetricsLabels),
+  }
+}
+
+func (h *HTTPMetrics) Component() string {
+  return httpMetricsComponent
+}
+
+func (h *HTTPMetrics) RegisterWith(registry *prometheus.Registry) {
+  registry.MustRegister(h.requests, h.duration, h.inFlight, h.responseSizes)
+}
+
+func (h *HTTPMetrics) middleware(c *gin.Context) {
+  start := time.Now()
+
+  h.inFlight.Inc()
+  defer h.inFlight.Dec()
+
+  c.Next()
+
+  labels := prometheus.Labels{
+    "method": c.Request.Method,
+    "route":  routeTemplate(c),
+    "status": statusClass(c.Writer.Status()),
+  }
+
+  h.requests.With(labels).Inc()
+  h.duration.With(labels).Observe(time.Since(start).Seconds())
+
+  if size := c.Writer.Size(); size >= 0 {
+    h.responseSizes.With(labels).Observe(float64(size))
+  }
+}
+
+func (r *Router) Pacer() *HTTPMetrics {
+  return r.metrics
+}
+
+func routeTemplate(c *gin.Context) string {
+  if route := c.FullPath(); route != "" {
+    return route
+  }
+
+  return "unmatched"
+}
+
+func statusClass(status int) string {
+  return fmt.Sprintf("%dxx", status/100)
+}
@@ -0,0 +1,104 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("HTTP metrics", func() {
+	var (
+		router   api.Router
+		registry *prometheus.Registry
+	)
+
+	families := func() map[string]*dto.MetricFamily {
+		gathered, err := registry.Gather()
+		Expect(err).To(BeNil())
+
+		byName := make(map[string]*dto.MetricFamily)
+		for _, family := range gathered {
+			byName[family.GetName()] = family
+		}
+
+		return byName
+	}
+
+	labelsOf := func(metric *dto.Metric) map[string]string {
+		labels := make(map[string]string)
+		for _, pair := range metric.GetLabel() {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+
+		return labels
+	}
+
+	perform := func(method, path string) {
+		router.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+
+	Context("when metrics are enabled", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api", Metrics: config.Metrics{Enabled: true}})
+
+			controller := api.NewController("/things")
+			controller.Register(api.NewGETRoute("/:id", func(c *gin.Context) {
+				c.String(http.StatusOK, "thing")
+			}))
+			router.Register(controller)
+
+			registry = prometheus.NewRegistry()
+
+			pacer := router.Pacer()
+			Expect(pacer).NotTo(BeNil())
+			Expect(pacer.Component()).To(Equal("http"))
+
+			pacer.RegisterWith(registry)
+		})
+
+		It("should count requests by method, route template and status class", func() {
+			perform(http.MethodGet, "/api/things/1")
+			perform(http.MethodGet, "/api/things/2")
+			perform(http.MethodGet, "/api/missing")
+
+			requests := families()["http_requests_total"]
+			Expect(requests).NotTo(BeNil())
+
+			counts := make(map[string]float64)
+			for _, metric := range requests.GetMetric() {
+				labels := labelsOf(metric)
+				counts[labels["method"]+" "+labels["route"]+" "+labels["status"]] = metric.GetCounter().GetValue()
+			}
+
+			Expect(counts).To(Equal(map[string]float64{
+				"GET /api/things/:id 2xx": 2,
+				"GET unmatched 4xx":       1,
+			}))
+		})
+
+		It("should observe latency, response size and in-flight requests", func() {
+			perform(http.MethodGet, "/api/things/1")
+
+			gathered := families()
+
+			Expect(gathered["http_request_duration_seconds"].GetMetric()[0].GetHistogram().GetSampleCount()).To(Equal(uint64(1)))
+			Expect(gathered["http_response_size_bytes"].GetMetric()[0].GetSummary().GetSampleSum()).To(Equal(float64(5)))
+			Expect(gathered["http_requests_in_flight"].GetMetric()[0].GetGauge().GetValue()).To(Equal(float64(0)))
+		})
+	})
+
+	Context("when metrics are disabled", func() {
+		It("should not expose a pacer", func() {
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+
+			Expect(router.Pacer()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,469 @@
+package api
+
+import (
+  "fmt"
+  "html"
+  "net/http"
+  "reflect"
+  "sort"
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/gin-gonic/gin"
+)
+
+var (
+  openAPIVersion       = "3.0.3"
+  bearerSecurityScheme = "bearerAuth"
+  timeType             = reflect.TypeOf(time.Time{})
+  bodyMethods          = map[string]bool{
+    http.MethodPost:  true,
+    http.MethodPut:   true,
+    http.MethodPatch: true,
+  }
+  swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>%s</title>
+  <link rel="stylesheet" href="%s/swagger-ui.css"%s>
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="%s/swagger-ui-bundle.js"%s></script>
+  <script src="swagger-initializer.js"></script>
+</body>
+</html>`
+  swaggerUIInitializer = `window.onload = function() {
+  SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});
+};
+`
+)
+
+type OpenAPIDocument struct {
+  OpenAPI    string                                  `json:"openapi"`
+  Info       OpenAPIInfo                             `json:"info"`
+  Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+  Components *OpenAPIComponents                      `json:"components,omitempty"`
+}
+
+type OpenAPIInfo struct {
+  Title   string `json:"title"`
+  Version string `json:"version"`
+}
+
+type OpenAPIComponents struct {
+  SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type OpenAPISecurityScheme struct {
+  Type         string `json:"type"`
+  Scheme       string `json:"scheme"`
+  BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type OpenAPIOperation struct {
+  Summary     string                     `json:"summary,omitempty"`
+  Tags        []string                   `json:"tags,omitempty"`
+  Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+  RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+  Responses   map[string]OpenAPIResponse `json:"responses"`
+  Security    []map[string][]string      `json:"security,omitempty"`
+  Deprecated  bool                       `json:"deprecated,omitempty"`
+}
+
+type OpenAPIParameter struct {
+  Name     string  `json:"name"`
+  In       string  `json:"in"`
+  Required bool    `json:"required"`
+  Schema   *Schema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+  Required bool                        `json:"required"`
+  Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+  Description string                      `json:"description"`
+  Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+  Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+  Type                 string             `json:"type,omitempty"`
+  Format               string             `json:"format,omitempty"`
+  Items                *Schema            `json:"items,omitempty"`
+  Properties           map[string]*Schema `json:"properties,omitempty"`
+  AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+  Required             []string           `json:"required,omitempty"`
+  Nullable             bool               `json:"nullable,omitempty"`
+}
+
+func (r *Router) OpenAPI() OpenAPIDocument {
+  return r.openAPI(allVersions)
+}
+
+func (r *Router) VersionOpenAPI(version string) OpenAPIDocument {
+  return r.openAPI(forVersion(version))
+}
+
+func (r *Router) openAPI(include func(registeredRoute) bool) OpenAPIDocument {
+  info := r.api.Info()
+
+  title := r.api.OpenAPI.Title
+  if title == "" {
+    title = info.Name
+  }
+
+  doc := OpenAPIDocument{
+    OpenAPI: openAPIVersion,
+    Info: OpenAPIInfo{
+      Title:   title,
+      Version: info.Version.String(),
+    },
+    Paths: make(map[string]map[string]*OpenAPIOperation),
+  }
+
+  secured := false
+
+  for _, registered := range r.registry.all() {
+    if registered.route.hidden || !include(registered) {
+      continue
+    }
+
+    path := openAPIPath(registered.path)
+    if _, ok := doc.Paths[path]; !ok {
+      doc.Paths[path] = make(map[string]*OpenAPIOperation)
+    }
+
+    doc.Paths[path][strings.ToLower(registered.route.method)] = newOpenAPIOperation(registered)
+
+    secured = secured || registered.secured
+  }
+
+  if secured {
+    doc.Components = &OpenAPIComponents{
+      SecuritySchemes: map[string]OpenAPISecurityScheme{
+        bearerSecurityScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+      },
+    }
+  }
+
+  return doc
+}
+
+func newOpenAPIOperation(registered registeredRoute) *OpenAPIOperation {
+  route := registered.route
+
+  operation := &OpenAPIOperation{
+    Summary:    route.summary,
+    Parameters: openAPIParameters(registered.path, route.requestType),
+    Responses:  make(map[string]OpenAPIResponse),
+  }
+
+  if tag := strings.Trim(registered.controller, "/"); tag != "" {
+    operation.Tags = []string{tag}
+  }
+
+  if route.requestType != nil && bodyMethods[route.method] {
+    if body := bodySchema(route.requestType); len(body.Properties) > 0 {
+      operation.RequestBody = &OpenAPIRequestBody{
+        Required: true,
+        Content:  map[string]OpenAPIMediaType{"application/json": {Schema: body}},
+      }
+    }
+  }
+
+  for status, responseType := range route.responses {
+    response := OpenAPIResponse{Description: http.StatusText(status)}
+    if responseType != nil && status != http.StatusNoContent {
+      response.Content = map[string]OpenAPIMediaType{"application/json": {Schema: schemaFor(responseType)}}
+    }
+
+    operation.Responses[strconv.Itoa(status)] = response
+  }
+
+  if len(operation.Responses) == 0 {
+    operation.Responses[strconv.Itoa(http.StatusOK)] = OpenAPIResponse{Description: http.StatusText(http.StatusOK)}
+  }
+
+  operation.Responses["default"] = OpenAPIResponse{
+    Description: "Problem",
+    Content:     map[string]OpenAPIMediaType{ProblemContentType: {Schema: schemaFor(reflect.TypeOf(Error{}))}},
+  }
+
+  operation.Deprecated = registered.version.IsDeprecated()
+
+  if registered.secured {
+    operation.Security = []map[string][]string{{bearerSecurityScheme: {}}}
+  }
+
+  return operation
+}
+
+func openAPIPath(ginPath string) string {
+  segments := strings.Split(ginPath, "/")
+
+  for i, segment := range segments {
+    if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+      segments[i] = fmt.Sprintf("{%s}", segment[1:])
+    }
+  }
+
+  return strings.Join(segments, "/")
+}
+
+func openAPIParameters(ginPath string, requestType reflect.Type) []OpenAPIParameter {
+  parameters := make([]OpenAPIParameter, 0)
+  uriFields := make(map[string]*Schema)
+
+  structType := derefType(requestType)
+  if structType != nil && structType.Kind() == reflect.Struct {
+    for i := 0; i < structType.NumField(); i++ {
+      field := structType.Field(i)
+
+      if name := tagName(field, "uri"); name != "" {
+        uriFields[name] = schemaFor(field.Type)
+      }
+
+      if name := tagName(field, "form"); name != "" {
+        parameters = append(parameters, OpenAPIParameter{
+          Name:     name,
+          In:       "query",
+          Required: isRequired(field),
+          Schema:   schemaFor(field.Type),
+        })
+      }
+    }
+  }
+
+  for _, segment := range strings.Split(ginPath, "/") {
+    if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+      continue
+    }
+
+    name := segment[1:]
+
+    schema, ok := uriFields[name]
+    if !ok {
+      schema = &Schema{Type: "string"}
+    }
+
+    parameters = append(parameters, OpenAPIParameter{
+      Name:     name,
+      In:       "path",
+      Required: true,
+      Schema:   schema,
+    })
+  }
+
+  sort.SliceStable(parameters, func(i, j int) bool {
+    return parameters[i].In == "path" && parameters[j].In != "path"
+  })
+
+  return parameters
+}
+
+func bodySchema(requestType reflect.Type) *Schema {
+  structType := derefType(requestType)
+  if structType.Kind() != reflect.Struct {
+    return schemaFor(requestType)
+  }
+
+  schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+  for i := 0; i < structType.NumField(); i++ {
+    field := structType.Field(i)
+
+    if tagName(field, "uri") != "" || tagName(field, "form") != "" {
+      continue
+    }
+
+    name, ok := jsonName(field)
+    if !ok {
+      continue
+    }
+
+    schema.Properties[name] = schemaFor(field.Type)
+
+    if isRequired(field) {
+      schema.Required = append(schema.Required, name)
+    }
+  }
+
+  return schema
+}
+
+func schemaFor(t reflect.Type) *Schema {
+  return buildSchema(t, make(map[reflect.Type]bool))
+}
+
+func buildSchema(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+  if t == nil {
+    return &Schema{}
+  }
+
+  if t.Kind() == reflect.Ptr {
+    schema := buildSchema(t.Elem(), visiting)
+    schema.Nullable = true
+
+    return schema
+  }
+
+  if t == timeType {
+    return &Schema{Type: "string", Format: "date-time"}
+  }
+
+  switch t.Kind() {
+  case reflect.Bool:
+    return &Schema{Type: "boolean"}
+  case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+    return &Schema{Type: "integer", Format: "int32"}
+  case reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64:
+    return &Schema{Type: "integer", Format: "int64"}
+  case reflect.Float32:
+    return &Schema{Type: "number", Format: "float"}
+  case reflect.Float64:
+    return &Schema{Type: "number", Format: "double"}
+  case reflect.String:
+    return &Schema{Type: "string"}
+  case reflect.Slice, reflect.Array:
+    if t.Elem().Kind() == reflect.Uint8 {
+      return &Schema{Type: "string", Format: "byte"}
+    }
+
+    return &Schema{Type: "array", Items: buildSchema(t.Elem(), visiting)}
+  case reflect.Map:
+    return &Schema{Type: "object", AdditionalProperties: buildSchema(t.Elem(), visiting)}
+  case reflect.Struct:
+    if visiting[t] {
+      return &Schema{Type: "object"}
+    }
+
+    visiting[t] = true
+    defer delete(visiting, t)
+
+    schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+    for i := 0; i < t.NumField(); i++ {
+      field := t.Field(i)
+
+      name, ok := jsonName(field)
+      if !ok {
+        continue
+      }
+
+      schema.Properties[name] = buildSchema(field.Type, visiting)
+
+      if isRequired(field) {
+        schema.Required = append(schema.Required, name)
+      }
+    }
+
+    return schema
+  default:
+    return &Schema{}
+  }
+}
+
+func derefType(t reflect.Type) reflect.Type {
+  for t != nil && t.Kind() == reflect.Ptr {
+    t = t.Elem()
+  }
+
+  return t
+}
+
+func tagName(field reflect.StructField, tag string) string {
+  name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+  if name == "-" {
+    return ""
+  }
+
+  return name
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+  if field.PkgPath != "" {
+    return "", false
+  }
+
+  tag := field.Tag.Get("json")
+  if tag == "-" {
+    return "", false
+  }
+
+  if name := strings.SplitN(tag, ",", 2)[0]; name != "" {
+    return name, true
+  }
+
+  return field.Name, true
+}
+
+func isRequired(field reflect.StructField) bool {
+  for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+    if rule == "required" {
+      return true
+    }
+  }
+
+  return false
+}
+
+func newOpenAPIController(router *Router) Controller {
+  controller := NewController("")
+
+  specRoute := NewGETRoute("/openapi.json", func(c *gin.Context) {
+    if version := c.Query("version"); version != "" {
+      c.JSON(http.StatusOK, router.VersionOpenAPI(version))
+    } else {
+      c.JSON(http.StatusOK, router.OpenAPI())
+    }
+  })
+  specRoute.hidden = true
+
+  controller.Register(specRoute)
+
+  if router.api.OpenAPI.SwaggerUI {
+    conf := router.api.OpenAPI
+    assets := html.EscapeString(conf.SwaggerUIAssetsURL())
+    policy := swaggerUIContentSecurityPolicy(conf.SwaggerUIAssetsURL())
+
+    docsRoute := NewGETRoute("/docs", func(c *gin.Context) {
+      if c.Writer.Header().Get("Content-Security-Policy") != "" {
+        c.Header("Content-Security-Policy", policy)
+      }
+
+      page := fmt.Sprintf(swaggerUIPage,
+        html.EscapeString(router.OpenAPI().Info.Title),
+        assets, integrityAttributes(conf.SwaggerUICSSIntegrity),
+        assets, integrityAttributes(conf.SwaggerUIBundleIntegrity),
+      )
+
+      c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+    })
+    docsRoute.hidden = true
+
+    initializerRoute := NewGETRoute("/swagger-initializer.js", func(c *gin.Context) {
+      c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerUIInitializer))
+    })
+    initializerRoute.hidden = true
+
+    controller.Register(docsRoute)
+    controller.Register(initializerRoute)
+  }
+
+  return controller
+}
+
+func integrityAttributes(integrity string) string {
+  if integrity == "" {
+    return ""
+  }
+
+  return fmt.Sprintf(` integrity="%s" crossorigin="anonymous"`, html.EscapeString(integrity))
+}
@@ -0,0 +1,129 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type getWidgetRequest struct {
+	ID      int    `uri:"id" validate:"required"`
+	Include string `form:"include"`
+}
+
+var _ = Describe("OpenAPI", func() {
+	var (
+		ts     *httptest.Server
+		router api.Router
+	)
+
+	BeforeEach(func() {
+		router = api.NewRouter(config.API{
+			PathPrefix: "/api",
+			OpenAPI: config.OpenAPI{
+				Enabled:   true,
+				SwaggerUI: true,
+				Title:     "Widgets",
+			},
+		})
+
+		widgets := api.NewController("/widgets")
+		widgets.Register(api.NewTypedGETRoute("/:id", func(ctx context.Context, req getWidgetRequest) (widgetResponse, error) {
+			return widgetResponse{}, nil
+		}).WithSummary("Get a widget"))
+		widgets.Register(api.NewTypedPOSTRoute("/", func(ctx context.Context, req createWidgetRequest) (*widgetResponse, error) {
+			return nil, nil
+		}).RequiresAuth())
+		widgets.Register(api.NewDELETERoute("/:id", func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		}).WithResponse(http.StatusNoContent, nil))
+
+		router.Register(widgets)
+
+		ts = httptest.NewServer(router.Handler())
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("should describe registered routes", func() {
+		res := makeRequest(fmt.Sprintf("%s/api/openapi.json", ts.URL), http.MethodGet)
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		var doc api.OpenAPIDocument
+		Expect(json.Unmarshal([]byte(resData(res)), &doc)).To(Succeed())
+
+		Expect(doc.OpenAPI).To(HavePrefix("3."))
+		Expect(doc.Info.Title).To(Equal("Widgets"))
+		Expect(doc.Paths).To(HaveKey("/api/info/version"))
+		Expect(doc.Paths).ToNot(HaveKey("/api/openapi.json"))
+
+		get := doc.Paths["/api/widgets/{id}"]["get"]
+		Expect(get).ToNot(BeNil())
+		Expect(get.Summary).To(Equal("Get a widget"))
+		Expect(get.Tags).To(Equal([]string{"widgets"}))
+		Expect(get.Parameters).To(HaveLen(2))
+		Expect(get.Parameters[0].In).To(Equal("path"))
+		Expect(get.Parameters[0].Schema.Type).To(Equal("integer"))
+		Expect(get.Parameters[1].Name).To(Equal("include"))
+		Expect(get.Responses["200"].Content["application/json"].Schema.Properties).To(HaveKey("dryRun"))
+		Expect(get.Security).To(BeEmpty())
+
+		post := doc.Paths["/api/widgets/"]["post"]
+		Expect(post).ToNot(BeNil())
+		Expect(post.RequestBody.Content["application/json"].Schema.Properties).To(HaveKey("name"))
+		Expect(post.RequestBody.Content["application/json"].Schema.Properties).ToNot(HaveKey("Owner"))
+		Expect(post.RequestBody.Content["application/json"].Schema.Required).To(ConsistOf("name"))
+		Expect(post.Responses).To(HaveKey("201"))
+		Expect(post.Responses["default"].Content).To(HaveKey(api.ProblemContentType))
+		Expect(post.Security).To(HaveLen(1))
+		Expect(doc.Components.SecuritySchemes).To(HaveKey("bearerAuth"))
+
+		del := doc.Paths["/api/widgets/{id}"]["delete"]
+		Expect(del.Responses).To(HaveKey("204"))
+		Expect(del.Responses["204"].Content).To(BeEmpty())
+	})
+
+	It("should serve the Swagger UI page", func() {
+		res := makeRequest(fmt.Sprintf("%s/api/docs", ts.URL), http.MethodGet)
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+		page := resData(res)
+		Expect(page).To(ContainSubstring("swagger-ui"))
+		Expect(page).To(ContainSubstring(`src="https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui-bundle.js"`))
+		Expect(page).To(ContainSubstring(`<script src="swagger-initializer.js"></script>`))
+
+		initializer := makeRequest(fmt.Sprintf("%s/api/swagger-initializer.js", ts.URL), http.MethodGet)
+		Expect(initializer.StatusCode).To(Equal(http.StatusOK))
+		Expect(resData(initializer)).To(ContainSubstring("SwaggerUIBundle"))
+	})
+
+	It("should pin configured Swagger UI assets with their integrity", func() {
+		pinned := api.NewRouter(config.API{
+			PathPrefix: "/api",
+			OpenAPI: config.OpenAPI{
+				Enabled:                  true,
+				SwaggerUI:                true,
+				SwaggerUIAssets:          "/assets/swagger-ui/",
+				SwaggerUICSSIntegrity:    "sha384-css",
+				SwaggerUIBundleIntegrity: "sha384-bundle",
+			},
+		})
+
+		rr := httptest.NewRecorder()
+		pinned.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.String()).To(ContainSubstring(`href="/assets/swagger-ui/swagger-ui.css" integrity="sha384-css" crossorigin="anonymous"`))
+		Expect(rr.Body.String()).To(ContainSubstring(`src="/assets/swagger-ui/swagger-ui-bundle.js" integrity="sha384-bundle" crossorigin="anonymous"`))
+	})
+})
@@ -0,0 +1,309 @@
+package api
+
+import (
+  "bytes"
+  "encoding/base64"
+  "encoding/json"
+  "fmt"
+  "regexp"
+  "sort"
+  "strconv"
+  "strings"
+
+  "github.com/gin-gonic/gin"
+)
+
+type FilterOperator string
+
+const (
+  FilterEq   FilterOperator = "eq"
+  FilterNe   FilterOperator = "ne"
+  FilterGt   FilterOperator = "gt"
+  FilterGte  FilterOperator = "gte"
+  FilterLt   FilterOperator = "lt"
+  FilterLte  FilterOperator = "lte"
+  FilterLike FilterOperator = "like"
+  FilterIn   FilterOperator = "in"
+)
+
+var (
+  defaultListLimit = 20
+  maxListLimit     = 100
+  defaultKeyColumn = "id"
+  filterParam      = regexp.MustCompile(`^filter\[([^\]]+)\](?:\[([^\]]+)\])?$`)
+  filterOperators  = map[FilterOperator]bool{
+    FilterEq:   true,
+    FilterNe:   true,
+    FilterGt:   true,
+    FilterGte:  true,
+    FilterLt:   true,
+    FilterLte:  true,
+    FilterLike: true,
+    FilterIn:   true,
+  }
+)
+
+type ListSpec struct {
+  Sortable     []string
+  Filterable   []string
+  Columns      map[string]string
+  DefaultSort  []SortField
+  DefaultLimit int
+  MaxLimit     int
+  Keyset       bool
+  KeyColumn    string
+}
+
+type SortField struct {
+  Field  string
+  Column string
+  Desc   bool
+}
+
+type Filter struct {
+  Field    string
+  Column   string
+  Operator FilterOperator
+  Values   []string
+}
+
+type Cursor struct {
+  Values   []interface{} `json:"v"`
+  Backward bool          `json:"b,omitempty"`
+}
+
+type ListOptions struct {
+  Page         int
+  Limit        int
+  Sort         []SortField
+  Filters      []Filter
+  Cursor       *Cursor
+  Keyset       bool
+  IncludeTotal bool
+}
+
+func (o ListOptions) Offset() int {
+  if o.Keyset || o.Page <= 1 {
+    return 0
+  }
+
+  return (o.Page - 1) * o.Limit
+}
+
+type Page struct {
+  Items      interface{} `json:"items"`
+  Page       int         `json:"page,omitempty"`
+  Limit      int         `json:"limit"`
+  Total      *int64      `json:"total,omitempty"`
+  HasMore    bool        `json:"hasMore"`
+  NextCursor string      `json:"nextCursor,omitempty"`
+  PrevCursor string      `json:"prevCursor,omitempty"`
+}
+
+func ParseListOptions(c *gin.Context, spec ListSpec) (ListOptions, error) {
+  query := c.Request.URL.Query()
+
+  opts := ListOptions{
+    Page:         1,
+    Limit:        spec.defaultLimit(),
+    Keyset:       spec.Keyset,
+    IncludeTotal: query.Get("total") == "true",
+  }
+
+  if raw := query.Get("limit"); raw != "" {
+    limit, err := strconv.Atoi(raw)
+    if err != nil || limit < 1 {
+      return opts, ErrBadRequest("`limit` must be a positive integer")
+    }
+
+    if limit > spec.maxLimit() {
+      limit = spec.maxLimit()
+    }
+
+    opts.Limit = limit
+  }
+
+  if raw := query.Get("page"); raw != "" {
+    page, err := strconv.Atoi(raw)
+    if err != nil || page < 1 {
+      return opts, ErrBadRequest("`page` must be a positive integer")
+    }
+
+    opts.Page = page
+  }
+
+  sortFields, err := spec.parseSort(query.Get("sort"))
+  if err != nil {
+    return opts, err
+  }
+
+  opts.Sort = sortFields
+
+  if opts.Keyset {
+    opts.Sort = spec.withKeyColumn(opts.Sort)
+
+    if raw := query.Get("cursor"); raw != "" {
+      cursor, err := DecodeCursor(raw)
+      if err != nil || len(cursor.Values) != len(opts.Sort) {
+        return opts, ErrBadRequest("`cursor` is invalid")
+      }
+
+      opts.Cursor = cursor
+    }
+  }
+
+  keys := make([]string, 0, len(query))
+  for key := range query {
+    keys = append(keys, key)
+  }
+
+  sort.Strings(keys)
+
+  for _, key := range keys {
+    matches := filterParam.FindStringSubmatch(key)
+    if matches == nil {
+      continue
+    }
+
+    filter, err := spec.parseFilter(matches[1], FilterOperator(matches[2]), query[key])
+    if err != nil {
+      return opts, err
+    }
+
+    opts.Filters = append(opts.Filters, filter)
+  }
+
+  return opts, nil
+}
+
+func (s ListSpec) defaultLimit() int {
+  if s.DefaultLimit > 0 {
+    return s.DefaultLimit
+  }
+
+  return defaultListLimit
+}
+
+func (s ListSpec) maxLimit() int {
+  if s.MaxLimit > 0 {
+    return s.MaxLimit
+  }
+
+  return maxListLimit
+}
+
+func (s ListSpec) column(field string) string {
+  if column, ok := s.Columns[field]; ok {
+    return column
+  }
+
+  return field
+}
+
+func (s ListSpec) parseSort(raw string) ([]SortField, error) {
+  if raw == "" {
+    sort := make([]SortField, len(s.DefaultSort))
+    for i, field := range s.DefaultSort {
+      if field.Column == "" {
+        field.Column = s.column(field.Field)
+      }
+
+      sort[i] = field
+    }
+
+    return sort, nil
+  }
+
+  sort := make([]SortField, 0)
+
+  for _, part := range strings.Split(raw, ",") {
+    desc := strings.HasPrefix(part, "-")
+    field := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+    if !contains(s.Sortable, field) {
+      return nil, ErrBadRequest(fmt.Sprintf("cannot sort by `%s`", field))
+    }
+
+    sort = append(sort, SortField{Field: field, Column: s.column(field), Desc: desc})
+  }
+
+  return sort, nil
+}
+
+func (s ListSpec) withKeyColumn(sort []SortField) []SortField {
+  key := s.KeyColumn
+  if key == "" {
+    key = defaultKeyColumn
+  }
+
+  for _, field := range sort {
+    if field.Column == key {
+      return sort
+    }
+  }
+
+  return append(sort, SortField{Field: key, Column: key})
+}
+
+func (s ListSpec) parseFilter(field string, operator FilterOperator, values []string) (Filter, error) {
+  if !contains(s.Filterable, field) {
+    return Filter{}, ErrBadRequest(fmt.Sprintf("cannot filter by `%s`", field))
+  }
+
+  if operator == "" {
+    operator = FilterEq
+  }
+
+  if !filterOperators[operator] {
+    return Filter{}, ErrBadRequest(fmt.Sprintf("unknown filter operator `%s`", operator))
+  }
+
+  if operator == FilterIn {
+    split := make([]string, 0)
+    for _, value := range values {
+      split = append(split, strings.Split(value, ",")...)
+    }
+
+    values = split
+  } else {
+    values = values[:1]
+  }
+
+  return Filter{Field: field, Column: s.column(field), Operator: operator, Values: values}, nil
+}
+
+func EncodeCursor(cursor Cursor) string {
+  encoded, err := json.Marshal(cursor)
+  if err != nil {
+    return ""
+  }
+
+  return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func DecodeCursor(raw string) (*Cursor, error) {
+  decoded, err := base64.RawURLEncoding.DecodeString(raw)
+  if err != nil {
+    return nil, err
+  }
+
+  decoder := json.NewDecoder(bytes.NewReader(decoded))
+  decoder.UseNumber()
+
+  var cursor Cursor
+  if err := decoder.Decode(&cursor); err != nil {
+    return nil, err
+  }
+
+  return &cursor, nil
+}
+
+func contains(values []string, value string) bool {
+  for _, v := range values {
+    if v == value {
+      return true
+    }
+  }
+
+  return false
+}
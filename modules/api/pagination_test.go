@@ -0,0 +1,123 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+)
+
+var _ = Describe("ParseListOptions", func() {
+	var (
+		spec = api.ListSpec{
+			Sortable:    []string{"name", "createdAt"},
+			Filterable:  []string{"status", "age"},
+			Columns:     map[string]string{"createdAt": "created_at"},
+			DefaultSort: []api.SortField{{Field: "createdAt", Desc: true}},
+			MaxLimit:    50,
+		}
+	)
+
+	parse := func(spec api.ListSpec, query string) (api.ListOptions, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/things?"+query, nil)
+
+		return api.ParseListOptions(c, spec)
+	}
+
+	It("should apply defaults", func() {
+		opts, err := parse(spec, "")
+		Expect(err).To(BeNil())
+
+		Expect(opts.Page).To(Equal(1))
+		Expect(opts.Limit).To(Equal(20))
+		Expect(opts.Offset()).To(Equal(0))
+		Expect(opts.Sort).To(Equal([]api.SortField{{Field: "createdAt", Column: "created_at", Desc: true}}))
+		Expect(opts.IncludeTotal).To(BeFalse())
+	})
+
+	It("should parse offset pagination and clamp the limit", func() {
+		opts, err := parse(spec, "page=3&limit=500&total=true")
+		Expect(err).To(BeNil())
+
+		Expect(opts.Limit).To(Equal(50))
+		Expect(opts.Offset()).To(Equal(100))
+		Expect(opts.IncludeTotal).To(BeTrue())
+	})
+
+	It("should parse whitelisted sort fields", func() {
+		opts, err := parse(spec, "sort=-createdAt,name")
+		Expect(err).To(BeNil())
+
+		Expect(opts.Sort).To(Equal([]api.SortField{
+			{Field: "createdAt", Column: "created_at", Desc: true},
+			{Field: "name", Column: "name"},
+		}))
+	})
+
+	It("should parse filter operators", func() {
+		opts, err := parse(spec, "filter[status][in]=active,pending&filter[age][gte]=18")
+		Expect(err).To(BeNil())
+
+		Expect(opts.Filters).To(Equal([]api.Filter{
+			{Field: "age", Column: "age", Operator: api.FilterGte, Values: []string{"18"}},
+			{Field: "status", Column: "status", Operator: api.FilterIn, Values: []string{"active", "pending"}},
+		}))
+	})
+
+	It("should default filters to equality", func() {
+		opts, err := parse(spec, "filter[status]=active")
+		Expect(err).To(BeNil())
+
+		Expect(opts.Filters[0].Operator).To(Equal(api.FilterEq))
+	})
+
+	It("should reject invalid parameters with a bad request problem", func() {
+		for _, query := range []string{
+			"page=abc",
+			"limit=0",
+			"sort=password",
+			"filter[password]=x",
+			"filter[status][regex]=x",
+		} {
+			_, err := parse(spec, query)
+			Expect(err).NotTo(BeNil(), query)
+			Expect(api.AsError(err).Status).To(Equal(http.StatusBadRequest), query)
+		}
+	})
+
+	Context("when keyset pagination is enabled", func() {
+		var (
+			keyset = api.ListSpec{Sortable: []string{"name"}, Keyset: true}
+		)
+
+		It("should append the key column as a tie breaker", func() {
+			opts, err := parse(keyset, "sort=name")
+			Expect(err).To(BeNil())
+
+			Expect(opts.Sort).To(Equal([]api.SortField{{Field: "name", Column: "name"}, {Field: "id", Column: "id"}}))
+			Expect(opts.Offset()).To(Equal(0))
+		})
+
+		It("should decode cursors", func() {
+			cursor := api.EncodeCursor(api.Cursor{Values: []interface{}{"alice", 42}, Backward: true})
+
+			opts, err := parse(keyset, "sort=name&cursor="+cursor)
+			Expect(err).To(BeNil())
+
+			Expect(opts.Cursor.Backward).To(BeTrue())
+			Expect(opts.Cursor.Values).To(Equal([]interface{}{"alice", json.Number("42")}))
+		})
+
+		It("should reject cursors that do not match the sort", func() {
+			cursor := api.EncodeCursor(api.Cursor{Values: []interface{}{42}})
+
+			_, err := parse(keyset, "sort=name&cursor="+cursor)
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
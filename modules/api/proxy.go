@@ -0,0 +1,543 @@
+package api
+
+import (
+  "bufio"
+  "bytes"
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "io/ioutil"
+  "net"
+  "net/http"
+  "net/http/httputil"
+  "net/url"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+)
+
+type CircuitState int8
+
+const (
+  CircuitClosed CircuitState = iota
+  CircuitOpen
+  CircuitHalfOpen
+)
+
+var (
+  ErrInvalidProxyTarget = func(target string) error {
+    return fmt.Errorf("invalid proxy target `%s`, expected an absolute URL", target)
+  }
+  ErrUpstreamUnhealthy = func(status int) error {
+    return fmt.Errorf("upstream health check returned status %d", status)
+  }
+  ErrCircuitOpen                 = errors.New("upstream circuit breaker is open")
+  ErrUpstreamTimeout             = errors.New("upstream did not respond in time")
+  ErrHijackUnsupported           = errors.New("response writer does not support connection upgrades")
+  circuitStateDisplay            = []string{"closed", "open", "half-open"}
+  defaultProxyTimeout            = 30 * time.Second
+  defaultProxyRetryBackoff       = 100 * time.Millisecond
+  defaultCircuitFailureThreshold = 5
+  defaultCircuitOpenTimeout      = 30 * time.Second
+  defaultCircuitHalfOpenRequests = 1
+  proxyMethods                   = []string{
+    http.MethodGet,
+    http.MethodHead,
+    http.MethodPost,
+    http.MethodPut,
+    http.MethodPatch,
+    http.MethodDelete,
+    http.MethodOptions,
+  }
+  idempotentMethods = map[string]bool{
+    http.MethodGet:     true,
+    http.MethodHead:    true,
+    http.MethodOptions: true,
+    http.MethodTrace:   true,
+    http.MethodPut:     true,
+    http.MethodDelete:  true,
+  }
+)
+
+func (s CircuitState) String() string {
+  return circuitStateDisplay[s]
+}
+
+type CircuitBreakerOptions struct {
+  Disabled         bool
+  FailureThreshold int
+  OpenTimeout      time.Duration
+  HalfOpenRequests int
+}
+
+type ProxyOptions struct {
+  Name                  string
+  PreservePath          bool
+  PreserveHost          bool
+  SetRequestHeaders     map[string]string
+  RemoveRequestHeaders  []string
+  SetResponseHeaders    map[string]string
+  RemoveResponseHeaders []string
+  Timeout               time.Duration
+  Retries               int
+  RetryBackoff          time.Duration
+  CircuitBreaker        CircuitBreakerOptions
+  HealthPath            string
+  Transport             http.RoundTripper
+}
+
+func (o ProxyOptions) withDefaults(target *url.URL) ProxyOptions {
+  if o.Name == "" {
+    o.Name = target.Host
+  }
+
+  if o.Timeout <= 0 {
+    o.Timeout = defaultProxyTimeout
+  }
+
+  if o.RetryBackoff <= 0 {
+    o.RetryBackoff = defaultProxyRetryBackoff
+  }
+
+  if o.CircuitBreaker.FailureThreshold <= 0 {
+    o.CircuitBreaker.FailureThreshold = defaultCircuitFailureThreshold
+  }
+
+  if o.CircuitBreaker.OpenTimeout <= 0 {
+    o.CircuitBreaker.OpenTimeout = defaultCircuitOpenTimeout
+  }
+
+  if o.CircuitBreaker.HalfOpenRequests <= 0 {
+    o.CircuitBreaker.HalfOpenRequests = defaultCircuitHalfOpenRequests
+  }
+
+  if o.Transport == nil {
+    o.Transport = http.DefaultTransport
+  }
+
+  return o
+}
+
+type Proxy struct {
+  target  *url.URL
+  opts    ProxyOptions
+  breaker *circuitBreaker
+  reverse *httputil.ReverseProxy
+}
+
+func NewProxy(target string, opts ProxyOptions) *Proxy {
+  upstream, err := url.Parse(target)
+  if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+    panic(ErrInvalidProxyTarget(target))
+  }
+
+  opts = opts.withDefaults(upstream)
+
+  p := &Proxy{
+    target:  upstream,
+    opts:    opts,
+    breaker: &circuitBreaker{conf: opts.CircuitBreaker},
+  }
+
+  p.reverse = &httputil.ReverseProxy{
+    Director:       p.direct,
+    Transport:      &proxyTransport{p},
+    ModifyResponse: p.modifyResponse,
+    ErrorHandler:   p.handleError,
+  }
+
+  return p
+}
+
+func NewProxyController(uri string, proxy *Proxy) Controller {
+  controller := NewController(uri)
+
+  for _, method := range proxyMethods {
+    for _, subPath := range []string{"", "/*path"} {
+      route := newRoute(method, subPath, proxy.handle)
+      route.hidden = true
+
+      controller.Register(route)
+    }
+  }
+
+  return controller
+}
+
+func (p *Proxy) Name() string {
+  return p.opts.Name
+}
+
+func (p *Proxy) CircuitState() CircuitState {
+  return p.breaker.currentState()
+}
+
+func (p *Proxy) Check(ctx context.Context) error {
+  if p.breaker.currentState() == CircuitOpen {
+    return ErrCircuitOpen
+  }
+
+  if p.opts.HealthPath == "" {
+    return nil
+  }
+
+  healthURL := *p.target
+  healthURL.Path = joinProxyPath(p.target.Path, p.opts.HealthPath)
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+  if err != nil {
+    return err
+  }
+
+  resp, err := p.opts.Transport.RoundTrip(req)
+  if err != nil {
+    return err
+  }
+  defer resp.Body.Close()
+
+  _, _ = io.Copy(ioutil.Discard, resp.Body)
+
+  if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+    return ErrUpstreamUnhealthy(resp.StatusCode)
+  }
+
+  return nil
+}
+
+func (p *Proxy) handle(c *gin.Context) {
+  req := c.Request.Clone(c.Request.Context())
+
+  forwardPath := c.Request.URL.Path
+  if !p.opts.PreservePath {
+    forwardPath = c.Param("path")
+  }
+
+  req.URL.Path = joinProxyPath(p.target.Path, forwardPath)
+  req.URL.RawPath = ""
+
+  if p.opts.Retries > 0 && idempotentMethods[req.Method] && req.Body != nil && req.Body != http.NoBody && req.ContentLength != 0 {
+    body, err := ioutil.ReadAll(req.Body)
+    if err != nil {
+      RenderError(c, err)
+
+      return
+    }
+
+    req.ContentLength = int64(len(body))
+    req.Body = ioutil.NopCloser(bytes.NewReader(body))
+    req.GetBody = func() (io.ReadCloser, error) {
+      return ioutil.NopCloser(bytes.NewReader(body)), nil
+    }
+  }
+
+  if id := RequestID(c); id != "" {
+    req.Header.Set(RequestIDHeader, id)
+  }
+
+  proto := "http"
+  if c.Request.TLS != nil {
+    proto = "https"
+  }
+
+  req.Header.Set("X-Forwarded-Host", c.Request.Host)
+  req.Header.Set("X-Forwarded-Proto", proto)
+
+  p.reverse.ServeHTTP(proxyWriter{c.Writer}, req)
+}
+
+func (p *Proxy) direct(req *http.Request) {
+  req.URL.Scheme = p.target.Scheme
+  req.URL.Host = p.target.Host
+
+  if !p.opts.PreserveHost {
+    req.Host = p.target.Host
+  }
+
+  if p.target.RawQuery != "" {
+    if req.URL.RawQuery == "" {
+      req.URL.RawQuery = p.target.RawQuery
+    } else {
+      req.URL.RawQuery = p.target.RawQuery + "&" + req.URL.RawQuery
+    }
+  }
+
+  for _, name := range p.opts.RemoveRequestHeaders {
+    req.Header.Del(name)
+  }
+
+  for name, value := range p.opts.SetRequestHeaders {
+    req.Header.Set(name, value)
+  }
+
+  if _, ok := req.Header["User-Agent"]; !ok {
+    req.Header.Set("User-Agent", "")
+  }
+}
+
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+  for _, name := range p.opts.RemoveResponseHeaders {
+    resp.Header.Del(name)
+  }
+
+  for name, value := range p.opts.SetResponseHeaders {
+    resp.Header.Set(name, value)
+  }
+
+  return nil
+}
+
+func (p *Proxy) handleError(w http.ResponseWriter, req *http.Request, err error) {
+  var apiErr *Error
+
+  switch {
+  case errors.Is(err, ErrRequestBodyTooLarge):
+    apiErr = AsError(err)
+  case errors.Is(err, ErrCircuitOpen):
+    w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(p.breaker.retryAfter())))
+    apiErr = NewError(http.StatusServiceUnavailable, "upstream_unavailable", fmt.Sprintf("upstream `%s` is unavailable", p.opts.Name))
+  case errors.Is(err, ErrUpstreamTimeout):
+    apiErr = ErrGatewayTimeout(fmt.Sprintf("upstream `%s` did not respond in time", p.opts.Name))
+  default:
+    apiErr = ErrBadGateway(fmt.Sprintf("upstream `%s` request failed", p.opts.Name))
+  }
+
+  logg.Root().
+    WithContext(req.Context()).
+    WithField("upstream", p.opts.Name).
+    WithField("method", req.Method).
+    WithField("path", req.URL.Path).
+    WithError(err).
+    Warn("Proxy request failed")
+
+  writeProblem(w, req, apiErr)
+}
+
+func joinProxyPath(base, suffix string) string {
+  joined := strings.TrimRight(base, "/") + suffix
+  if suffix != "" && !strings.HasPrefix(suffix, "/") {
+    joined = strings.TrimRight(base, "/") + "/" + suffix
+  }
+
+  if joined == "" {
+    return "/"
+  }
+
+  return joined
+}
+
+type proxyWriter struct {
+  http.ResponseWriter
+}
+
+func (w proxyWriter) Flush() {
+  if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+    flusher.Flush()
+  }
+}
+
+func (w proxyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+  if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+    return hijacker.Hijack()
+  }
+
+  return nil, nil, ErrHijackUnsupported
+}
+
+type proxyTransport struct {
+  proxy *Proxy
+}
+
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+  p := t.proxy
+
+  attempts := 1
+  if p.opts.Retries > 0 && idempotentMethods[req.Method] && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+    attempts += p.opts.Retries
+  }
+
+  for attempt := 1; ; attempt++ {
+    if !p.breaker.allow() {
+      return nil, ErrCircuitOpen
+    }
+
+    resp, err := p.attempt(req, attempt)
+    if err != nil && req.Context().Err() != nil {
+      p.breaker.abandon()
+
+      return nil, err
+    }
+
+    failed := err != nil || isUpstreamFailure(resp.StatusCode)
+    p.breaker.record(!failed)
+
+    if !failed || attempt >= attempts {
+      return resp, err
+    }
+
+    if resp != nil {
+      _, _ = io.Copy(ioutil.Discard, resp.Body)
+      resp.Body.Close()
+    }
+
+    select {
+    case <-req.Context().Done():
+      return nil, req.Context().Err()
+    case <-time.After(p.opts.RetryBackoff * time.Duration(attempt)):
+    }
+  }
+}
+
+func (p *Proxy) attempt(req *http.Request, attempt int) (*http.Response, error) {
+  ctx, cancel := context.WithTimeout(req.Context(), p.opts.Timeout)
+
+  out := req.Clone(ctx)
+  if attempt > 1 && req.GetBody != nil {
+    body, err := req.GetBody()
+    if err != nil {
+      cancel()
+
+      return nil, err
+    }
+
+    out.Body = body
+  }
+
+  resp, err := p.opts.Transport.RoundTrip(out)
+  if err != nil {
+    cancel()
+
+    if errors.Is(ctx.Err(), context.DeadlineExceeded) && req.Context().Err() == nil {
+      return nil, fmt.Errorf("%w: %v", ErrUpstreamTimeout, err)
+    }
+
+    return nil, err
+  }
+
+  body := &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+  if conn, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+    resp.Body = &upgradedBody{cancelOnClose: body, Writer: conn}
+  } else {
+    resp.Body = body
+  }
+
+  return resp, nil
+}
+
+func isUpstreamFailure(status int) bool {
+  return status == http.StatusBadGateway ||
+    status == http.StatusServiceUnavailable ||
+    status == http.StatusGatewayTimeout
+}
+
+type cancelOnClose struct {
+  io.ReadCloser
+  cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+  defer b.cancel()
+
+  return b.ReadCloser.Close()
+}
+
+type upgradedBody struct {
+  *cancelOnClose
+  io.Writer
+}
+
+type circuitBreaker struct {
+  mu       sync.Mutex
+  conf     CircuitBreakerOptions
+  state    CircuitState
+  failures int
+  openedAt time.Time
+  probes   int
+}
+
+func (b *circuitBreaker) allow() bool {
+  if b.conf.Disabled {
+    return true
+  }
+
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  switch b.state {
+  case CircuitOpen:
+    if time.Since(b.openedAt) < b.conf.OpenTimeout {
+      return false
+    }
+
+    b.state, b.probes = CircuitHalfOpen, 0
+
+    fallthrough
+  case CircuitHalfOpen:
+    if b.probes >= b.conf.HalfOpenRequests {
+      return false
+    }
+
+    b.probes++
+  }
+
+  return true
+}
+
+func (b *circuitBreaker) record(success bool) {
+  if b.conf.Disabled {
+    return
+  }
+
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  if success {
+    b.state, b.failures, b.probes = CircuitClosed, 0, 0
+
+    return
+  }
+
+  b.failures++
+  if b.state == CircuitHalfOpen || b.failures >= b.conf.FailureThreshold {
+    b.state, b.openedAt, b.probes = CircuitOpen, time.Now(), 0
+  }
+}
+
+func (b *circuitBreaker) abandon() {
+  if b.conf.Disabled {
+    return
+  }
+
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  if b.state == CircuitHalfOpen && b.probes > 0 {
+    b.probes--
+  }
+}
+
+func (b *circuitBreaker) currentState() CircuitState {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  if b.state == CircuitOpen && time.Since(b.openedAt) >= b.conf.OpenTimeout {
+    return CircuitHalfOpen
+  }
+
+  return b.state
+}
+
+func (b *circuitBreaker) retryAfter() time.Duration {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  if remaining := b.conf.OpenTimeout - time.Since(b.openedAt); remaining > 0 {
+    return remaining
+  }
+
+  return 0
+}
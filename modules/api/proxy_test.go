@@ -0,0 +1,306 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type upstreamEcho struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query"`
+	Host    string            `json:"host"`
+	Body    string            `json:"body"`
+	Headers map[string]string `json:"headers"`
+}
+
+var _ = Describe("Proxy", func() {
+	var (
+		router   api.Router
+		upstream *httptest.Server
+		proxy    *api.Proxy
+		calls    int32
+		failures int32
+		delay    time.Duration
+	)
+
+	perform := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	echoOf := func(rr *httptest.ResponseRecorder) upstreamEcho {
+		var echo upstreamEcho
+		Expect(json.Unmarshal(rr.Body.Bytes(), &echo)).To(Succeed())
+
+		return echo
+	}
+
+	mount := func(opts api.ProxyOptions) {
+		proxy = api.NewProxy(upstream.URL+"/legacy", opts)
+
+		router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+		router.Register(api.NewProxyController("/backend", proxy))
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt32(&calls, 0)
+		atomic.StoreInt32(&failures, 0)
+		delay = 0
+
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+
+			if r.Header.Get("Upgrade") == "echo" {
+				conn, buf, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+				_ = buf.Flush()
+				_, _ = io.Copy(conn, buf)
+
+				return
+			}
+
+			if r.URL.Path == "/legacy/health" {
+				w.WriteHeader(http.StatusOK)
+
+				return
+			}
+
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			body, _ := ioutil.ReadAll(r.Body)
+			headers := make(map[string]string)
+			for name := range r.Header {
+				headers[name] = r.Header.Get(name)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Server", "legacy/1.0")
+			_ = json.NewEncoder(w).Encode(upstreamEcho{
+				Method:  r.Method,
+				Path:    r.URL.Path,
+				Query:   r.URL.RawQuery,
+				Host:    r.Host,
+				Body:    string(body),
+				Headers: headers,
+			})
+		}))
+	})
+
+	AfterEach(func() {
+		upstream.Close()
+	})
+
+	It("should forward requests beneath the prefix with rewritten headers", func() {
+		mount(api.ProxyOptions{
+			Name:                  "legacy",
+			SetRequestHeaders:     map[string]string{"X-Gateway": "agora"},
+			RemoveRequestHeaders:  []string{"Cookie"},
+			SetResponseHeaders:    map[string]string{"X-Proxied-By": "agora"},
+			RemoveResponseHeaders: []string{"Server"},
+		})
+
+		rr := perform(http.MethodPost, "/api/backend/orders/7?expand=lines", `{"qty":1}`, map[string]string{
+			"Cookie":        "session=abc",
+			"Authorization": "Bearer token",
+		})
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Header().Get("X-Proxied-By")).To(Equal("agora"))
+		Expect(rr.Header().Get("Server")).To(BeEmpty())
+
+		echo := echoOf(rr)
+		Expect(echo.Method).To(Equal(http.MethodPost))
+		Expect(echo.Path).To(Equal("/legacy/orders/7"))
+		Expect(echo.Query).To(Equal("expand=lines"))
+		Expect(echo.Host).To(Equal(strings.TrimPrefix(upstream.URL, "http://")))
+		Expect(echo.Body).To(Equal(`{"qty":1}`))
+		Expect(echo.Headers).To(HaveKeyWithValue("X-Gateway", "agora"))
+		Expect(echo.Headers).To(HaveKeyWithValue("Authorization", "Bearer token"))
+		Expect(echo.Headers).To(HaveKeyWithValue("X-Forwarded-Proto", "http"))
+		Expect(echo.Headers).To(HaveKeyWithValue(http.CanonicalHeaderKey(api.RequestIDHeader), rr.Header().Get(api.RequestIDHeader)))
+		Expect(echo.Headers).To(HaveKey("X-Forwarded-For"))
+		Expect(echo.Headers).ToNot(HaveKey("Cookie"))
+
+		Expect(echoOf(perform(http.MethodGet, "/api/backend", "", nil)).Path).To(Equal("/legacy"))
+	})
+
+	It("should overwrite forwarded headers supplied by the client", func() {
+		mount(api.ProxyOptions{})
+
+		echo := echoOf(perform(http.MethodGet, "/api/backend/orders", "", map[string]string{
+			"X-Forwarded-Host":  "evil.example",
+			"X-Forwarded-Proto": "https",
+		}))
+
+		Expect(echo.Headers).To(HaveKeyWithValue("X-Forwarded-Host", "example.com"))
+		Expect(echo.Headers).To(HaveKeyWithValue("X-Forwarded-Proto", "http"))
+	})
+
+	It("should preserve the full request path when configured", func() {
+		mount(api.ProxyOptions{PreservePath: true})
+
+		Expect(echoOf(perform(http.MethodGet, "/api/backend/orders", "", nil)).Path).To(Equal("/legacy/api/backend/orders"))
+	})
+
+	It("should retry idempotent requests and replay their bodies", func() {
+		mount(api.ProxyOptions{Retries: 2, RetryBackoff: time.Millisecond})
+		atomic.StoreInt32(&failures, 2)
+
+		rr := perform(http.MethodPut, "/api/backend/orders/7", `{"qty":2}`, nil)
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(echoOf(rr).Body).To(Equal(`{"qty":2}`))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+	})
+
+	It("should not retry non-idempotent requests", func() {
+		mount(api.ProxyOptions{Retries: 2, RetryBackoff: time.Millisecond})
+		atomic.StoreInt32(&failures, 1)
+
+		Expect(perform(http.MethodPost, "/api/backend/orders", `{}`, nil).Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+	})
+
+	It("should open the circuit after repeated failures and recover", func() {
+		mount(api.ProxyOptions{
+			CircuitBreaker: api.CircuitBreakerOptions{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond},
+		})
+		atomic.StoreInt32(&failures, 2)
+
+		Expect(perform(http.MethodGet, "/api/backend/orders", "", nil).Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(perform(http.MethodGet, "/api/backend/orders", "", nil).Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(proxy.CircuitState()).To(Equal(api.CircuitOpen))
+		Expect(proxy.Check(context.Background())).To(MatchError(api.ErrCircuitOpen))
+
+		rejected := perform(http.MethodGet, "/api/backend/orders", "", nil)
+		Expect(rejected.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(rejected.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+		Expect(rejected.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(rejected.Body.String()).To(ContainSubstring("upstream_unavailable"))
+		Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+
+		Eventually(proxy.CircuitState).Should(Equal(api.CircuitHalfOpen))
+
+		Expect(perform(http.MethodGet, "/api/backend/orders", "", nil).Code).To(Equal(http.StatusOK))
+		Expect(proxy.CircuitState()).To(Equal(api.CircuitClosed))
+	})
+
+	It("should not count client cancellations against the upstream", func() {
+		mount(api.ProxyOptions{
+			CircuitBreaker: api.CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute},
+		})
+		delay = time.Second
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/backend/slow", nil).WithContext(ctx)
+		router.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		Expect(proxy.CircuitState()).To(Equal(api.CircuitClosed))
+		Expect(perform(http.MethodGet, "/api/backend/orders", "", nil).Code).To(Equal(http.StatusOK))
+	})
+
+	It("should respond with 504 when the upstream times out", func() {
+		mount(api.ProxyOptions{Timeout: 20 * time.Millisecond})
+		delay = time.Second
+
+		rr := perform(http.MethodGet, "/api/backend/slow", "", nil)
+
+		Expect(rr.Code).To(Equal(http.StatusGatewayTimeout))
+		Expect(rr.Body.String()).To(ContainSubstring("gateway_timeout"))
+	})
+
+	It("should respond with 502 when the upstream is unreachable", func() {
+		mount(api.ProxyOptions{})
+		upstream.Close()
+
+		rr := perform(http.MethodGet, "/api/backend/orders", "", nil)
+
+		Expect(rr.Code).To(Equal(http.StatusBadGateway))
+		Expect(rr.Body.String()).To(ContainSubstring("bad_gateway"))
+	})
+
+	It("should pass protocol upgrades through to the upstream", func() {
+		mount(api.ProxyOptions{Timeout: 20 * time.Millisecond})
+
+		server := httptest.NewServer(router.Handler())
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		Expect(err).To(BeNil())
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("GET /api/backend/stream HTTP/1.1\r\nHost: agora\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n"))
+		Expect(err).To(BeNil())
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		Expect(err).To(BeNil())
+		Expect(resp.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+
+		time.Sleep(50 * time.Millisecond)
+
+		_, err = conn.Write([]byte("ping"))
+		Expect(err).To(BeNil())
+
+		Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+
+		reply := make([]byte, 4)
+		_, err = io.ReadFull(reader, reply)
+		Expect(err).To(BeNil())
+		Expect(string(reply)).To(Equal("ping"))
+	})
+
+	It("should check upstream health", func() {
+		mount(api.ProxyOptions{HealthPath: "/health"})
+
+		Expect(proxy.Check(context.Background())).To(Succeed())
+
+		upstream.Close()
+		Expect(proxy.Check(context.Background())).ToNot(Succeed())
+	})
+
+	It("should panic on invalid targets", func() {
+		Expect(func() { api.NewProxy("not a url", api.ProxyOptions{}) }).To(Panic())
+	})
+})
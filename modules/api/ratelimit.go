@@ -0,0 +1,263 @@
+package api
+
+import (
+  "context"
+  "fmt"
+  "math"
+  "net"
+  "net/http"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  defaultAPIKeyHeader       = "X-API-Key"
+  forwardedForHeader        = "X-Forwarded-For"
+  memoryRateLimitPruneEvery = time.Minute
+)
+
+type RateLimitResult struct {
+  Allowed    bool
+  Limit      int
+  Remaining  int
+  Reset      time.Duration
+  RetryAfter time.Duration
+}
+
+type RateLimitStore interface {
+  Take(ctx context.Context, key string, rule config.RateLimitRule) (RateLimitResult, error)
+}
+
+type TokenBucket struct {
+  Tokens  float64
+  Updated time.Time
+}
+
+func (b TokenBucket) Take(now time.Time, rule config.RateLimitRule) (TokenBucket, RateLimitResult) {
+  capacity := float64(bucketCapacity(rule))
+  rate := float64(rule.Requests) / rule.Window.Seconds()
+
+  tokens := capacity
+  if !b.Updated.IsZero() {
+    tokens = math.Min(capacity, b.Tokens+now.Sub(b.Updated).Seconds()*rate)
+  }
+
+  result := RateLimitResult{Limit: int(capacity)}
+
+  if tokens >= 1 {
+    tokens--
+    result.Allowed = true
+  } else {
+    result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+  }
+
+  result.Remaining = int(math.Floor(tokens))
+  result.Reset = secondsToDuration((capacity - tokens) / rate)
+
+  return TokenBucket{Tokens: tokens, Updated: now}, result
+}
+
+func bucketCapacity(rule config.RateLimitRule) int {
+  if rule.Burst > 0 {
+    return rule.Burst
+  }
+
+  return rule.Requests
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+  return time.Duration(seconds * float64(time.Second))
+}
+
+type MemoryRateLimitStore struct {
+  mu         sync.Mutex
+  buckets    map[string]memoryBucket
+  lastPruned time.Time
+}
+
+type memoryBucket struct {
+  bucket  TokenBucket
+  expires time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+  return &MemoryRateLimitStore{
+    buckets:    make(map[string]memoryBucket),
+    lastPruned: time.Now(),
+  }
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rule config.RateLimitRule) (RateLimitResult, error) {
+  now := time.Now()
+
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  if now.Sub(s.lastPruned) >= memoryRateLimitPruneEvery {
+    s.prune(now)
+  }
+
+  bucket, result := s.buckets[key].bucket.Take(now, rule)
+  s.buckets[key] = memoryBucket{bucket: bucket, expires: now.Add(result.Reset)}
+
+  return result, nil
+}
+
+func (s *MemoryRateLimitStore) prune(now time.Time) {
+  for key, entry := range s.buckets {
+    if !now.Before(entry.expires) {
+      delete(s.buckets, key)
+    }
+  }
+
+  s.lastPruned = now
+}
+
+type rateLimiter struct {
+  mu      sync.RWMutex
+  conf    config.RateLimit
+  store   RateLimitStore
+  trusted []*net.IPNet
+}
+
+func newRateLimiter(conf config.RateLimit) *rateLimiter {
+  trusted := make([]*net.IPNet, len(conf.TrustedProxies))
+  for i, proxy := range conf.TrustedProxies {
+    network, err := config.ParseTrustedProxy(proxy)
+    if err != nil {
+      panic(err)
+    }
+
+    trusted[i] = network
+  }
+
+  return &rateLimiter{conf: conf, store: NewMemoryRateLimitStore(), trusted: trusted}
+}
+
+func (r *Router) UseRateLimitStore(store RateLimitStore) {
+  r.limiter.mu.Lock()
+  defer r.limiter.mu.Unlock()
+
+  r.limiter.store = store
+}
+
+func (l *rateLimiter) ruleFor(route Route, fullPath string) (string, config.RateLimitRule, bool) {
+  if !route.rateLimit.IsZero() {
+    return fmt.Sprintf("%s %s", route.method, fullPath), route.rateLimit, true
+  }
+
+  if !l.conf.Enabled {
+    return "", config.RateLimitRule{}, false
+  }
+
+  if rule, ok := l.conf.Rule(route.method, fullPath); ok {
+    return fmt.Sprintf("%s %s", route.method, fullPath), rule, true
+  }
+
+  return "global", l.conf.Default, true
+}
+
+func (l *rateLimiter) middleware(scope string, rule config.RateLimitRule) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    l.mu.RLock()
+    store := l.store
+    l.mu.RUnlock()
+
+    result, err := store.Take(c.Request.Context(), fmt.Sprintf("%s|%s", scope, l.clientKey(c, rule.Key)), rule)
+    if err != nil {
+      logg.Root().WithContext(c.Request.Context()).WithError(err).Warn("Rate limit store unavailable, allowing request")
+      c.Next()
+
+      return
+    }
+
+    c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+    c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+    c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+    if !result.Allowed {
+      c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+      RenderError(c, ErrTooManyRequests(fmt.Sprintf("rate limit of %d requests per %s exceeded", rule.Requests, rule.Window)))
+
+      return
+    }
+
+    c.Next()
+  }
+}
+
+func (l *rateLimiter) clientKey(c *gin.Context, key config.RateLimitKey) string {
+  switch key {
+  case config.RateLimitKeySubject:
+    if subject := c.GetString("subject"); subject != "" {
+      return "subject:" + subject
+    }
+  case config.RateLimitKeyAPIKey:
+    header := l.conf.APIKeyHeader
+    if header == "" {
+      header = defaultAPIKeyHeader
+    }
+
+    if apiKey := c.GetHeader(header); apiKey != "" {
+      return "apiKey:" + apiKey
+    }
+  }
+
+  return "ip:" + l.clientIP(c.Request)
+}
+
+func (l *rateLimiter) clientIP(req *http.Request) string {
+  client := remoteIP(req.RemoteAddr)
+  if !l.isTrusted(client) {
+    return client
+  }
+
+  hops := strings.Split(strings.Join(req.Header.Values(forwardedForHeader), ","), ",")
+  for i := len(hops) - 1; i >= 0; i-- {
+    hop := strings.TrimSpace(hops[i])
+    if hop == "" {
+      continue
+    }
+
+    client = hop
+    if !l.isTrusted(hop) {
+      break
+    }
+  }
+
+  return client
+}
+
+func (l *rateLimiter) isTrusted(addr string) bool {
+  ip := net.ParseIP(addr)
+  if ip == nil {
+    return false
+  }
+
+  for _, network := range l.trusted {
+    if network.Contains(ip) {
+      return true
+    }
+  }
+
+  return false
+}
+
+func remoteIP(addr string) string {
+  host, _, err := net.SplitHostPort(addr)
+  if err != nil {
+    return strings.TrimSpace(addr)
+  }
+
+  return host
+}
+
+func ceilSeconds(d time.Duration) int {
+  return int(math.Ceil(d.Seconds()))
+}
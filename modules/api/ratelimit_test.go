@@ -0,0 +1,197 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, config.RateLimitRule) (api.RateLimitResult, error) {
+	return api.RateLimitResult{}, errors.New("store unavailable")
+}
+
+var _ = Describe("Rate limiting", func() {
+	var (
+		router api.Router
+	)
+
+	okHandler := func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	}
+
+	perform := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	Context("when a global rate limit is configured", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{
+				Port:       8123,
+				PathPrefix: "/api",
+				RateLimit: config.RateLimit{
+					Enabled: true,
+					Default: config.RateLimitRule{Requests: 2, Window: time.Minute, Burst: 2, Key: config.RateLimitKeyAPIKey},
+					Routes: map[string]config.RateLimitRule{
+						"POST /api/things/login": {Requests: 1, Window: time.Minute, Burst: 1, Key: config.RateLimitKeyIP},
+					},
+				},
+			})
+
+			controller := api.NewController("/things")
+			controller.Register(api.NewGETRoute("", okHandler))
+			controller.Register(api.NewPOSTRoute("/login", okHandler))
+			router.Register(controller)
+		})
+
+		It("should report the remaining quota", func() {
+			rr := perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+
+			Expect(rr.Code).To(Equal(http.StatusNoContent))
+			Expect(rr.Header().Get("RateLimit-Limit")).To(Equal("2"))
+			Expect(rr.Header().Get("RateLimit-Remaining")).To(Equal("1"))
+			Expect(rr.Header().Get("RateLimit-Reset")).To(Equal("30"))
+		})
+
+		It("should respond with 429 once the quota is exhausted", func() {
+			perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+			perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+			rr := perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+
+			Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+			Expect(rr.Header().Get("Retry-After")).To(Equal("30"))
+			Expect(rr.Header().Get("RateLimit-Remaining")).To(Equal("0"))
+		})
+
+		It("should keep separate buckets per API key", func() {
+			perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+			perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "abc"})
+			rr := perform(http.MethodGet, "/api/things", map[string]string{"X-API-Key": "def"})
+
+			Expect(rr.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should apply configured per-route rules instead of the default", func() {
+			Expect(perform(http.MethodPost, "/api/things/login", nil).Code).To(Equal(http.StatusNoContent))
+			Expect(perform(http.MethodPost, "/api/things/login", nil).Code).To(Equal(http.StatusTooManyRequests))
+			Expect(perform(http.MethodGet, "/api/things", nil).Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should allow requests when the store fails", func() {
+			router.UseRateLimitStore(failingRateLimitStore{})
+
+			for i := 0; i < 3; i++ {
+				Expect(perform(http.MethodPost, "/api/things/login", nil).Code).To(Equal(http.StatusNoContent))
+			}
+		})
+	})
+
+	Context("when a route declares its own rate limit", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+
+			controller := api.NewController("/things")
+			controller.Use(func(c *gin.Context) {
+				c.Set("subject", c.GetHeader("X-User"))
+			})
+			controller.Register(api.NewGETRoute("", okHandler).WithRateLimit(config.RateLimitRule{Requests: 1, Key: config.RateLimitKeySubject}))
+			controller.Register(api.NewGETRoute("/unlimited", okHandler))
+			router.Register(controller)
+		})
+
+		It("should key the bucket by subject", func() {
+			Expect(perform(http.MethodGet, "/api/things", map[string]string{"X-User": "alice"}).Code).To(Equal(http.StatusNoContent))
+			Expect(perform(http.MethodGet, "/api/things", map[string]string{"X-User": "alice"}).Code).To(Equal(http.StatusTooManyRequests))
+			Expect(perform(http.MethodGet, "/api/things", map[string]string{"X-User": "bob"}).Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("should leave other routes unlimited", func() {
+			for i := 0; i < 3; i++ {
+				rr := perform(http.MethodGet, "/api/things/unlimited", nil)
+				Expect(rr.Code).To(Equal(http.StatusNoContent))
+				Expect(rr.Header().Get("RateLimit-Limit")).To(BeEmpty())
+			}
+		})
+	})
+
+	Context("when clients are keyed by IP address", func() {
+		performFrom := func(remoteAddr, forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+			req.RemoteAddr = remoteAddr
+			if forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+			}
+
+			rr := httptest.NewRecorder()
+			router.Handler().ServeHTTP(rr, req)
+
+			return rr.Code
+		}
+
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{
+				Port:       8123,
+				PathPrefix: "/api",
+				RateLimit: config.RateLimit{
+					Enabled:        true,
+					Default:        config.RateLimitRule{Requests: 1, Window: time.Minute, Burst: 1, Key: config.RateLimitKeyIP},
+					TrustedProxies: []string{"10.0.0.0/8"},
+				},
+			})
+
+			controller := api.NewController("/things")
+			controller.Register(api.NewGETRoute("", okHandler))
+			router.Register(controller)
+		})
+
+		It("should ignore X-Forwarded-For from untrusted peers", func() {
+			Expect(performFrom("192.0.2.1:4711", "198.51.100.1")).To(Equal(http.StatusNoContent))
+			Expect(performFrom("192.0.2.1:4711", "198.51.100.2")).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("should honour X-Forwarded-For from trusted proxies", func() {
+			Expect(performFrom("10.0.0.5:4711", "198.51.100.1, 10.0.0.7")).To(Equal(http.StatusNoContent))
+			Expect(performFrom("10.0.0.5:4711", "198.51.100.2")).To(Equal(http.StatusNoContent))
+			Expect(performFrom("10.0.0.6:4711", "203.0.113.9, 198.51.100.1")).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+})
+
+var _ = Describe("TokenBucket", func() {
+	rule := config.RateLimitRule{Requests: 10, Window: 10 * time.Second, Burst: 2}
+
+	It("should refill at the configured rate", func() {
+		now := time.Now()
+
+		bucket, result := api.TokenBucket{}.Take(now, rule)
+		Expect(result.Allowed).To(BeTrue())
+
+		bucket, result = bucket.Take(now, rule)
+		Expect(result.Allowed).To(BeTrue())
+
+		bucket, result = bucket.Take(now, rule)
+		Expect(result.Allowed).To(BeFalse())
+		Expect(result.RetryAfter).To(Equal(time.Second))
+
+		_, result = bucket.Take(now.Add(time.Second), rule)
+		Expect(result.Allowed).To(BeTrue())
+	})
+})
@@ -0,0 +1,58 @@
+package api
+
+import (
+  "crypto/rand"
+  "fmt"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/logg"
+)
+
+var (
+  RequestIDHeader    = "X-Request-ID"
+  maxRequestIDLength = 128
+)
+
+func RequestIDMiddleware(c *gin.Context) {
+  id := c.GetHeader(RequestIDHeader)
+  if !isValidRequestID(id) {
+    id = NewRequestID()
+  }
+
+  c.Request = c.Request.WithContext(logg.ContextWithRequestID(c.Request.Context(), id))
+  c.Header(RequestIDHeader, id)
+
+  c.Next()
+}
+
+func RequestID(c *gin.Context) string {
+  id, _ := logg.RequestIDFromContext(c.Request.Context())
+
+  return id
+}
+
+func isValidRequestID(id string) bool {
+  if id == "" || len(id) > maxRequestIDLength {
+    return false
+  }
+
+  for _, r := range id {
+    if r < 0x21 || r > 0x7e {
+      return false
+    }
+  }
+
+  return true
+}
+
+func NewRequestID() string {
+  b := make([]byte, 16)
+  if _, err := rand.Read(b); err != nil {
+    panic(err)
+  }
+
+  b[6] = (b[6] & 0x0f) | 0x40
+  b[8] = (b[8] & 0x3f) | 0x80
+
+  return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
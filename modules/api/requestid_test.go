@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Request ID", func() {
+	var (
+		router api.Router
+	)
+
+	BeforeEach(func() {
+		router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+
+		controller := api.NewController("/echo")
+		controller.Register(api.NewGETRoute("", func(c *gin.Context) {
+			fromContext, _ := logg.RequestIDFromContext(c.Request.Context())
+
+			c.JSON(http.StatusOK, map[string]string{
+				"requestId": api.RequestID(c),
+				"context":   fromContext,
+			})
+		}))
+		router.Register(controller)
+	})
+
+	perform := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/echo", nil)
+		if id != "" {
+			req.Header.Set(api.RequestIDHeader, id)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	It("should accept and echo a provided request ID", func() {
+		rr := perform("abc-123")
+
+		Expect(rr.Header().Get(api.RequestIDHeader)).To(Equal("abc-123"))
+		Expect(rr.Body.String()).To(Equal(`{"context":"abc-123","requestId":"abc-123"}`))
+	})
+
+	It("should generate a request ID when none is provided", func() {
+		rr := perform("")
+
+		id := rr.Header().Get(api.RequestIDHeader)
+		Expect(id).To(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		Expect(rr.Body.String()).To(ContainSubstring(id))
+	})
+
+	It("should replace a request ID that is not safe to log", func() {
+		rr := perform(strings.Repeat("x", 200))
+
+		Expect(rr.Header().Get(api.RequestIDHeader)).To(HaveLen(36))
+	})
+
+	It("should echo the request ID on problem responses", func() {
+		req := httptest.NewRequest(http.MethodGet, "/api/missing", nil)
+		req.Header.Set(api.RequestIDHeader, "missing-1")
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		Expect(rr.Code).To(Equal(http.StatusNotFound))
+		Expect(rr.Header().Get(api.RequestIDHeader)).To(Equal("missing-1"))
+	})
+})
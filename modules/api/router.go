@@ -2,7 +2,10 @@ package api
 
 import (
   "net/http"
+  "path"
+  "reflect"
   "sync"
+  "time"
 
   "github.com/gin-contrib/cors"
   "github.com/gin-gonic/gin"
@@ -14,17 +17,44 @@ var (
 )
 
 type Router struct {
-  api    config.API
-  router *gin.Engine
+  api           config.API
+  router        *gin.Engine
+  registry      *routeRegistry
+  limiter       *rateLimiter
+  metrics       *HTTPMetrics
+  idempotency   *idempotency
+  streams       *streamRegistry
+  static        *staticFiles
+  authenticator *authenticator
 }
 
 func (r *Router) Server() *http.Server {
-  return &http.Server{
+  server := &http.Server{
     Addr:         r.api.ListenAddr(),
     Handler:      r.router,
     ReadTimeout:  r.api.Timeout.Read,
     WriteTimeout: r.api.Timeout.Write,
+    ConnContext:  withConn,
   }
+
+  if r.api.TLS.IsEnabled() {
+    tlsConfig, err := NewTLSConfig(r.api.TLS)
+    if err != nil {
+      panic(err)
+    }
+
+    server.TLSConfig = tlsConfig
+  }
+
+  return server
+}
+
+func (r *Router) ListenAndServe(server *http.Server) error {
+  if server.TLSConfig != nil {
+    return server.ListenAndServeTLS("", "")
+  }
+
+  return server.ListenAndServe()
 }
 
 func (r *Router) Handler() http.Handler {
@@ -32,72 +62,205 @@ func (r *Router) Handler() http.Handler {
 }
 
 type Controller struct {
-  uri    string
-  routes []Route
+  uri         string
+  routes      []Route
+  middleware  []func(ctx *gin.Context)
+  secured     bool
+  timeout     time.Duration
+  maxBodySize int64
 }
 
 type Route struct {
-  handler    func(ctx *gin.Context)
-  subPath    string
-  method     string
-  middleware func(ctx *gin.Context)
+  handler       func(ctx *gin.Context)
+  subPath       string
+  method        string
+  middleware    func(ctx *gin.Context)
+  summary       string
+  requestType   reflect.Type
+  responses     map[int]reflect.Type
+  secured       bool
+  hidden        bool
+  rateLimit     config.RateLimitRule
+  heartbeat     time.Duration
+  stream        func(streams *streamRegistry, heartbeat time.Duration) func(c *gin.Context)
+  streamHandler interface{}
+  timeout       time.Duration
+  maxBodySize   int64
 }
 
 func NewRouter(config config.API) Router {
-  r := gin.Default()
+  r := gin.New()
+  r.HandleMethodNotAllowed = true
+  r.Use(RequestIDMiddleware)
+
+  var metrics *HTTPMetrics
+  if config.Metrics.Enabled {
+    metrics = newHTTPMetrics(config.Metrics)
+    r.Use(metrics.middleware)
+  }
+
+  prefixes := []string{config.PathPrefix}
+  for _, version := range config.Versions {
+    prefixes = append(prefixes, version.Prefix)
+  }
+
+  if config.AccessLog.Enabled {
+    r.Use(AccessLogMiddleware(config.AccessLog, prefixes...))
+  }
+
+  if config.Compression.Enabled {
+    r.Use(CompressionMiddleware(config.Compression))
+  }
+
+  if config.Security.Enabled {
+    r.Use(SecurityHeadersMiddleware(config.Security))
+  }
+
+  r.Use(Recovery, ErrorHandler)
+  r.NoMethod(noMethodHandler)
 
   if config.ShouldRegisterCors() {
     r.Use(cors.New(config.Cors.ToGinConfig()))
   }
 
+  if config.Security.CSRF.Enabled {
+    r.Use(CSRFMiddleware(config.Security.CSRF, prefixes...))
+  }
+
   router := &Router{
-    api:    config,
-    router: r,
+    api:           config,
+    router:        r,
+    registry:      &routeRegistry{},
+    limiter:       newRateLimiter(config.RateLimit),
+    metrics:       metrics,
+    idempotency:   newIdempotency(config.Idempotency),
+    streams:       newStreamRegistry(config.WebSocket),
+    static:        newStaticFiles(config),
+    authenticator: &authenticator{},
   }
 
+  r.NoRoute(router.noRoute)
+
   infoController := NewInfoController(config.Info())
 
+  if config.ExposeRoutes {
+    infoController.Register(NewGETRoute("/routes", routesHandler(router)))
+  }
+
+  if len(config.Versions) > 0 {
+    infoController.Register(NewGETRoute("/versions", versionsHandler(router)))
+  }
+
   router.Register(infoController)
 
+  if config.OpenAPI.Enabled {
+    router.Register(newOpenAPIController(router))
+  }
+
   return *router
 }
 
 func NewController(uri string) Controller {
-  return Controller{uri, make([]Route, 0)}
+  return Controller{uri: uri, routes: make([]Route, 0), middleware: make([]func(ctx *gin.Context), 0)}
 }
 
 func (r *Router) Register(controller Controller) {
-  m.Lock()
-  defer m.Unlock()
+  r.RegisterWithMiddleware(controller)
+}
 
-  rg := r.routerGroup().Group(controller.uri)
+func (r *Router) RegisterWithMiddleware(controller Controller, middleware ...func(ctx *gin.Context)) {
+  version, _ := r.defaultVersion()
 
-  for _, route := range controller.routes {
-    if route.middleware != nil {
-      rg.Handle(route.method, route.subPath, route.handler).Use(route.middleware)
-    } else {
-      rg.Handle(route.method, route.subPath, route.handler)
-    }
-  }
+  r.register(r.api.PathPrefix, version, controller, middleware...)
 }
 
-func (r *Router) RegisterWithMiddleware(controller Controller, middleware func(ctx *gin.Context)) {
+func (r *Router) register(prefix string, version config.APIVersion, controller Controller, middleware ...func(ctx *gin.Context)) {
   m.Lock()
   defer m.Unlock()
 
-  rg := r.routerGroup().Group(controller.uri).Use(middleware)
+  handlers := make([]gin.HandlerFunc, 0, len(middleware)+len(controller.middleware)+1)
+  for _, mw := range middleware {
+    handlers = append(handlers, mw)
+  }
+
+  for _, mw := range controller.middleware {
+    handlers = append(handlers, mw)
+  }
+
+  rg := r.router.Group(prefix).Group(controller.uri)
 
   for _, route := range controller.routes {
+    fullPath := joinPaths(rg.BasePath(), route.subPath)
+    secured := controller.secured || route.secured
+
+    routeHandlers := make([]gin.HandlerFunc, 0, len(handlers)+8)
+    if version.IsDeprecated() {
+      routeHandlers = append(routeHandlers, deprecationMiddleware(version.Deprecation))
+    }
+
+    if secured {
+      routeHandlers = append(routeHandlers, r.authenticator.middleware)
+    }
+
+    routeHandlers = append(routeHandlers, handlers...)
+
+    if scope, rule, ok := r.limiter.ruleFor(route, fullPath); ok {
+      routeHandlers = append(routeHandlers, r.limiter.middleware(scope, rule))
+    }
+
+    bodyLimit := r.maxBodySizeFor(controller, route)
+    if bodyLimit > 0 {
+      routeHandlers = append(routeHandlers, bodyLimitMiddleware(bodyLimit))
+    }
+
+    if timeout := r.timeoutFor(controller, route); timeout > 0 {
+      routeHandlers = append(routeHandlers, timeoutMiddleware(timeout))
+    }
+
+    if r.idempotency.appliesTo(route) {
+      routeHandlers = append(routeHandlers, r.idempotency.middleware(bodyLimit))
+    }
+
     if route.middleware != nil {
-      rg.Handle(route.method, route.subPath, route.middleware, route.handler)
-    } else {
-      rg.Handle(route.method, route.subPath, route.handler)
+      routeHandlers = append(routeHandlers, route.middleware)
     }
+
+    handler, name := route.handler, handlerName(route.handler)
+    if route.stream != nil {
+      handler, name = route.stream(r.streams, heartbeatInterval(route)), handlerName(route.streamHandler)
+    }
+
+    chain := append(handlerNames(rg.Handlers), handlerNames(routeHandlers)...)
+
+    rg.Handle(route.method, route.subPath, append(routeHandlers, handler)...)
+
+    r.registry.add(registeredRoute{
+      path:       fullPath,
+      version:    version,
+      controller: controller.uri,
+      route:      route,
+      secured:    secured,
+      handler:    name,
+      middleware: chain,
+    })
+  }
+}
+
+func joinPaths(absolutePath, relativePath string) string {
+  if relativePath == "" {
+    return absolutePath
+  }
+
+  joined := path.Join(absolutePath, relativePath)
+  if relativePath[len(relativePath)-1] == '/' && joined[len(joined)-1] != '/' {
+    return joined + "/"
   }
+
+  return joined
 }
 
-func (r *Router) routerGroup() *gin.RouterGroup {
-  return r.router.Group(r.api.PathPrefix)
+func (c *Controller) Use(middleware ...func(ctx *gin.Context)) {
+  c.middleware = append(c.middleware, middleware...)
 }
 
 func (c *Controller) Register(route Route) {
@@ -105,16 +268,52 @@ func (c *Controller) Register(route Route) {
 }
 
 func (c *Controller) RegisterWithMiddleware(route Route, middleware func(ctx *gin.Context)) {
-  withMiddleware := Route{
-    handler: route.handler,
-    subPath: route.subPath,
-    method:  route.method,
-    middleware: middleware,
-  }
+  withMiddleware := route
+  withMiddleware.middleware = middleware
 
   c.routes = append(c.routes, withMiddleware)
 }
 
+func (c *Controller) RequiresAuth() {
+  c.secured = true
+}
+
+func (r Route) WithSummary(summary string) Route {
+  r.summary = summary
+
+  return r
+}
+
+func (r Route) WithRequest(example interface{}) Route {
+  r.requestType = reflect.TypeOf(example)
+
+  return r
+}
+
+func (r Route) WithResponse(status int, example interface{}) Route {
+  responses := make(map[int]reflect.Type, len(r.responses)+1)
+  for code, t := range r.responses {
+    responses[code] = t
+  }
+
+  responses[status] = reflect.TypeOf(example)
+  r.responses = responses
+
+  return r
+}
+
+func (r Route) WithRateLimit(rule config.RateLimitRule) Route {
+  r.rateLimit = rule.WithDefaults()
+
+  return r
+}
+
+func (r Route) RequiresAuth() Route {
+  r.secured = true
+
+  return r
+}
+
 func NewGETRoute(uri string, handler func(c *gin.Context)) Route {
   return newRoute(http.MethodGet, uri, handler)
 }
@@ -3,6 +3,7 @@ package api_test
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/wgentry22/agora/modules/api"
@@ -92,6 +93,190 @@ var _ = Describe("Router", func() {
 		})
 	})
 
+	Context("with controller and route middleware", func() {
+		It("should apply each middleware only where it was registered", func() {
+			mw := func(name string) func(c *gin.Context) {
+				return func(c *gin.Context) {
+					c.Header("X-"+name, "applied")
+					c.Next()
+				}
+			}
+
+			withMiddleware := api.NewController("/mw")
+			withMiddleware.Use(mw("Controller"))
+			withMiddleware.RegisterWithMiddleware(api.NewGETRoute("/route", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			}), mw("Route"))
+			withMiddleware.Register(api.NewGETRoute("/plain", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			}))
+
+			router.Register(withMiddleware)
+			ts = httptest.NewServer(router.Handler())
+
+			routeRes := makeRequest(fmt.Sprintf("%s/api/mw/route", ts.URL), http.MethodGet)
+			Expect(routeRes.Header.Get("X-Controller")).To(Equal("applied"))
+			Expect(routeRes.Header.Get("X-Route")).To(Equal("applied"))
+
+			plainRes := makeRequest(fmt.Sprintf("%s/api/mw/plain", ts.URL), http.MethodGet)
+			Expect(plainRes.Header.Get("X-Controller")).To(Equal("applied"))
+			Expect(plainRes.Header.Get("X-Route")).To(BeEmpty())
+		})
+	})
+
+	Context("when requests fail", func() {
+		var problem = func(res *http.Response) map[string]interface{} {
+			Expect(res.Header.Get("Content-Type")).To(HavePrefix(api.ProblemContentType))
+
+			var body map[string]interface{}
+			Expect(json.Unmarshal([]byte(resData(res)), &body)).To(Succeed())
+
+			return body
+		}
+
+		BeforeEach(func() {
+			failing := api.NewController("/failing")
+			failing.Register(api.NewGETRoute("/panic", func(c *gin.Context) {
+				panic("boom")
+			}))
+			failing.Register(api.NewGETRoute("/error", func(c *gin.Context) {
+				_ = c.Error(api.ErrNotFound("widget 1 does not exist"))
+			}))
+
+			router.Register(failing)
+			ts = httptest.NewServer(router.Handler())
+		})
+
+		It("should serve problem+json for unknown routes", func() {
+			body := problem(makeRequest(fmt.Sprintf("%s/api/unknown", ts.URL), http.MethodGet))
+
+			Expect(body["status"]).To(Equal(float64(http.StatusNotFound)))
+			Expect(body["title"]).To(Equal(http.StatusText(http.StatusNotFound)))
+			Expect(body["type"]).To(Equal("about:blank"))
+			Expect(body["instance"]).To(Equal("/api/unknown"))
+		})
+
+		It("should serve problem+json for unsupported methods", func() {
+			res := makeRequest(fmt.Sprintf("%s/api/failing/error", ts.URL), http.MethodPost)
+			Expect(res.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+			Expect(problem(res)["code"]).To(Equal("method_not_allowed"))
+		})
+
+		It("should serve problem+json for recovered panics", func() {
+			res := makeRequest(fmt.Sprintf("%s/api/failing/panic", ts.URL), http.MethodGet)
+			Expect(res.StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(problem(res)["code"]).To(Equal("internal_error"))
+		})
+
+		It("should render errors attached to the context", func() {
+			res := makeRequest(fmt.Sprintf("%s/api/failing/error", ts.URL), http.MethodGet)
+			Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(problem(res)["detail"]).To(Equal("widget 1 does not exist"))
+		})
+	})
+
+	Context("with routes that require auth", func() {
+		var secured api.Controller
+
+		BeforeEach(func() {
+			secured = api.NewController("/secure")
+			secured.Use(func(c *gin.Context) {
+				c.Header("X-Subject", c.GetString("subject"))
+				c.Next()
+			})
+			secured.Register(api.NewGETRoute("/private", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			}).RequiresAuth())
+			secured.Register(api.NewGETRoute("/public", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			}))
+		})
+
+		It("should reject secured routes when no authenticator is configured", func() {
+			router.Register(secured)
+			ts = httptest.NewServer(router.Handler())
+
+			Expect(makeRequest(fmt.Sprintf("%s/api/secure/private", ts.URL), http.MethodGet).StatusCode).To(Equal(http.StatusInternalServerError))
+			Expect(makeRequest(fmt.Sprintf("%s/api/secure/public", ts.URL), http.MethodGet).StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("should run the authenticator before controller middleware", func() {
+			router.Register(secured)
+			router.UseAuthenticator(func(c *gin.Context) {
+				if c.GetHeader("Authorization") == "" {
+					api.RenderError(c, api.ErrUnauthorized("missing credentials"))
+					return
+				}
+
+				c.Set("subject", "alice")
+			})
+			ts = httptest.NewServer(router.Handler())
+
+			Expect(makeRequest(fmt.Sprintf("%s/api/secure/private", ts.URL), http.MethodGet).StatusCode).To(Equal(http.StatusUnauthorized))
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/secure/private", ts.URL), nil)
+			Expect(err).To(BeNil())
+			req.Header.Set("Authorization", "Bearer token")
+
+			res, err := http.DefaultClient.Do(req)
+			Expect(err).To(BeNil())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+			Expect(res.Header.Get("X-Subject")).To(Equal("alice"))
+
+			public := makeRequest(fmt.Sprintf("%s/api/secure/public", ts.URL), http.MethodGet)
+			Expect(public.StatusCode).To(Equal(http.StatusOK))
+			Expect(public.Header.Get("X-Subject")).To(BeEmpty())
+		})
+	})
+
+	Context("when introspecting routes", func() {
+		It("should list every registered route with its middleware", func() {
+			router = api.NewRouter(config.API{
+				PathPrefix:   "/api",
+				ExposeRoutes: true,
+			})
+
+			router.RegisterWithMiddleware(controller, func(c *gin.Context) {
+				c.Next()
+			})
+
+			routes := router.Routes()
+
+			var infoRoute, deleteRoute api.RouteInfo
+			for _, route := range routes {
+				switch {
+				case route.Path == "/api/info/routes":
+					infoRoute = route
+				case route.Method == http.MethodDelete:
+					deleteRoute = route
+				}
+			}
+
+			Expect(infoRoute.Method).To(Equal(http.MethodGet))
+
+			Expect(deleteRoute.Path).To(Equal("/api/test/:id"))
+			Expect(deleteRoute.Controller).To(Equal("/test"))
+			Expect(deleteRoute.Middleware).To(ContainElement(ContainSubstring("api.ErrorHandler")))
+			Expect(deleteRoute.Middleware).To(ContainElement(ContainSubstring("api_test")))
+
+			ts = httptest.NewServer(router.Handler())
+
+			res := makeRequest(fmt.Sprintf("%s/api/info/routes", ts.URL), http.MethodGet)
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+			var served []api.RouteInfo
+			Expect(json.Unmarshal([]byte(resData(res)), &served)).To(Succeed())
+			Expect(served).To(HaveLen(len(routes)))
+		})
+
+		It("should not expose /info/routes unless configured", func() {
+			ts = httptest.NewServer(router.Handler())
+
+			res := makeRequest(fmt.Sprintf("%s/api/info/routes", ts.URL), http.MethodGet)
+			Expect(res.StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
 	Context("with registered controllers", func() {
 		It("should serve all endpoints", func() {
 			router.Register(controller)
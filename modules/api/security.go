@@ -0,0 +1,142 @@
+package api
+
+import (
+  "crypto/rand"
+  "crypto/subtle"
+  "encoding/base64"
+  "fmt"
+  "net/http"
+  "net/url"
+  "strings"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  csrfTokenKey    = "csrfToken"
+  csrfTokenBytes  = 32
+  csrfSafeMethods = map[string]bool{
+    http.MethodGet:     true,
+    http.MethodHead:    true,
+    http.MethodOptions: true,
+    http.MethodTrace:   true,
+  }
+)
+
+func swaggerUIContentSecurityPolicy(assets string) string {
+  sources := "'self'"
+  if parsed, err := url.Parse(assets); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+    sources += " " + parsed.Scheme + "://" + parsed.Host
+  }
+
+  return fmt.Sprintf("default-src 'self'; script-src %s; style-src %s; img-src 'self' data:; frame-ancestors 'none'", sources, sources)
+}
+
+func ErrCSRFTokenInvalid() *Error {
+  return NewError(http.StatusForbidden, "csrf_token_invalid", "missing or invalid CSRF token")
+}
+
+func SecurityHeadersMiddleware(conf config.Security) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    header := c.Writer.Header()
+
+    if conf.HSTS.Enabled && isSecureRequest(c.Request) {
+      header.Set("Strict-Transport-Security", conf.HSTS.HeaderValue())
+    }
+
+    if conf.ContentSecurityPolicy != "" {
+      header.Set("Content-Security-Policy", conf.ContentSecurityPolicy)
+    }
+
+    if conf.NoSniff {
+      header.Set("X-Content-Type-Options", "nosniff")
+    }
+
+    if conf.FrameOptions != "" {
+      header.Set("X-Frame-Options", conf.FrameOptions)
+    }
+
+    if conf.ReferrerPolicy != "" {
+      header.Set("Referrer-Policy", conf.ReferrerPolicy)
+    }
+
+    c.Next()
+  }
+}
+
+func isSecureRequest(req *http.Request) bool {
+  return req.TLS != nil || strings.EqualFold(req.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+func CSRFMiddleware(conf config.CSRF, pathPrefixes ...string) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    token := ""
+    if cookie, err := c.Request.Cookie(conf.CookieName); err == nil {
+      token = cookie.Value
+    }
+
+    if !csrfSafeMethods[c.Request.Method] && requiresCSRF(conf, pathPrefixes, c.Request) {
+      supplied := c.GetHeader(conf.HeaderName)
+      if token == "" || supplied == "" || subtle.ConstantTimeCompare([]byte(token), []byte(supplied)) != 1 {
+        RenderError(c, ErrCSRFTokenInvalid())
+
+        return
+      }
+    }
+
+    if token == "" {
+      issued, err := newCSRFToken()
+      if err != nil {
+        RenderError(c, err)
+
+        return
+      }
+
+      token = issued
+      http.SetCookie(c.Writer, &http.Cookie{
+        Name:     conf.CookieName,
+        Value:    token,
+        Path:     conf.CookiePath,
+        Domain:   conf.CookieDomain,
+        MaxAge:   int(conf.TTL.Seconds()),
+        Secure:   conf.Secure,
+        SameSite: conf.SameSite,
+      })
+    }
+
+    c.Set(csrfTokenKey, token)
+    c.Next()
+  }
+}
+
+func CSRFToken(c *gin.Context) string {
+  return c.GetString(csrfTokenKey)
+}
+
+func requiresCSRF(conf config.CSRF, pathPrefixes []string, req *http.Request) bool {
+  if isExcludedPath(conf.Exclude, pathPrefixes, req.URL.Path) {
+    return false
+  }
+
+  if len(conf.SessionCookies) == 0 {
+    return true
+  }
+
+  for _, name := range conf.SessionCookies {
+    if _, err := req.Cookie(name); err == nil {
+      return true
+    }
+  }
+
+  return false
+}
+
+func newCSRFToken() (string, error) {
+  raw := make([]byte, csrfTokenBytes)
+  if _, err := rand.Read(raw); err != nil {
+    return "", err
+  }
+
+  return base64.RawURLEncoding.EncodeToString(raw), nil
+}
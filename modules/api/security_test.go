@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Security", func() {
+	var (
+		router api.Router
+	)
+
+	okHandler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"token": api.CSRFToken(c)})
+	}
+
+	register := func() {
+		controller := api.NewController("/things")
+		controller.Register(api.NewGETRoute("", okHandler))
+		controller.Register(api.NewPOSTRoute("", okHandler))
+		controller.Register(api.NewPOSTRoute("/hooks", okHandler))
+
+		router.Register(controller)
+	}
+
+	perform := func(req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	Context("when security headers are enabled", func() {
+		BeforeEach(func() {
+			var security config.Security
+			Expect(security.UnmarshalTOML(map[string]interface{}{"enabled": true})).To(Succeed())
+
+			router = api.NewRouter(config.API{
+				Port:       8123,
+				PathPrefix: "/api",
+				Security:   security,
+				OpenAPI:    config.OpenAPI{Enabled: true, SwaggerUI: true},
+			})
+			register()
+		})
+
+		It("should set security headers on every response", func() {
+			for _, path := range []string{"/api/things", "/api/missing"} {
+				rr := perform(httptest.NewRequest(http.MethodGet, path, nil))
+
+				Expect(rr.Header().Get("Content-Security-Policy")).To(Equal("default-src 'self'; frame-ancestors 'none'"))
+				Expect(rr.Header().Get("X-Content-Type-Options")).To(Equal("nosniff"))
+				Expect(rr.Header().Get("X-Frame-Options")).To(Equal("DENY"))
+				Expect(rr.Header().Get("Referrer-Policy")).To(Equal("no-referrer"))
+				Expect(rr.Header().Get("Strict-Transport-Security")).To(BeEmpty())
+			}
+		})
+
+		It("should only send HSTS over secure connections", func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/things", nil)
+			req.TLS = &tls.ConnectionState{}
+			Expect(perform(req).Header().Get("Strict-Transport-Security")).To(Equal("max-age=31536000; includeSubDomains"))
+
+			req = httptest.NewRequest(http.MethodGet, "/api/things", nil)
+			req.Header.Set("X-Forwarded-Proto", "https")
+			Expect(perform(req).Header().Get("Strict-Transport-Security")).To(Equal("max-age=31536000; includeSubDomains"))
+		})
+
+		It("should relax the content security policy for the swagger UI", func() {
+			rr := perform(httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Header().Get("Content-Security-Policy")).To(ContainSubstring("https://unpkg.com"))
+			Expect(rr.Header().Get("Content-Security-Policy")).ToNot(ContainSubstring("unsafe-inline"))
+		})
+	})
+
+	Context("when CSRF protection is enabled", func() {
+		BeforeEach(func() {
+			var security config.Security
+			Expect(security.UnmarshalTOML(map[string]interface{}{
+				"csrf": map[string]interface{}{
+					"enabled":        true,
+					"ttl":            int64(time.Hour / time.Millisecond),
+					"sessionCookies": []interface{}{"session"},
+					"exclude":        []interface{}{"/things/hooks"},
+				},
+			})).To(Succeed())
+
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api", Security: security})
+			register()
+		})
+
+		issueToken := func() *http.Cookie {
+			rr := perform(httptest.NewRequest(http.MethodGet, "/api/things", nil))
+			Expect(rr.Code).To(Equal(http.StatusOK))
+
+			cookies := rr.Result().Cookies()
+			Expect(cookies).To(HaveLen(1))
+			Expect(cookies[0].Name).To(Equal("csrf_token"))
+			Expect(cookies[0].Secure).To(BeTrue())
+			Expect(cookies[0].HttpOnly).To(BeFalse())
+			Expect(cookies[0].SameSite).To(Equal(http.SameSiteStrictMode))
+			Expect(cookies[0].MaxAge).To(Equal(3600))
+			Expect(rr.Body.String()).To(ContainSubstring(cookies[0].Value))
+
+			return cookies[0]
+		}
+
+		post := func(path string, cookies []*http.Cookie, token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+
+			if token != "" {
+				req.Header.Set("X-CSRF-Token", token)
+			}
+
+			return perform(req)
+		}
+
+		It("should accept unsafe requests that echo the cookie in the header", func() {
+			cookie := issueToken()
+			session := &http.Cookie{Name: "session", Value: "abc"}
+
+			Expect(post("/api/things", []*http.Cookie{session, cookie}, cookie.Value).Code).To(Equal(http.StatusOK))
+		})
+
+		It("should reject cookie-authenticated requests with a missing or mismatched token", func() {
+			cookie := issueToken()
+			session := &http.Cookie{Name: "session", Value: "abc"}
+
+			missing := post("/api/things", []*http.Cookie{session, cookie}, "")
+			Expect(missing.Code).To(Equal(http.StatusForbidden))
+			Expect(missing.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+			Expect(missing.Body.String()).To(ContainSubstring("csrf_token_invalid"))
+
+			Expect(post("/api/things", []*http.Cookie{session, cookie}, "forged").Code).To(Equal(http.StatusForbidden))
+			Expect(post("/api/things", []*http.Cookie{session}, cookie.Value).Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should skip requests without session cookies and excluded paths", func() {
+			Expect(post("/api/things", nil, "").Code).To(Equal(http.StatusOK))
+			Expect(post("/api/things/hooks", []*http.Cookie{{Name: "session", Value: "abc"}}, "").Code).To(Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,164 @@
+package api
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "net/http"
+  "sync"
+  "time"
+
+  "github.com/gin-gonic/gin"
+)
+
+var (
+  ErrStreamClosed   = errors.New("stream is closed")
+  sseContentType    = "text/event-stream"
+  lastEventIDHeader = "Last-Event-ID"
+)
+
+type SSEStream struct {
+  mu     sync.Mutex
+  c      *gin.Context
+  ctx    context.Context
+  cancel context.CancelFunc
+}
+
+func NewSSERoute(uri string, handler func(stream *SSEStream)) Route {
+  route := newRoute(http.MethodGet, uri, nil)
+  route.streamHandler = handler
+  route.stream = func(streams *streamRegistry, heartbeat time.Duration) func(c *gin.Context) {
+    return sseHandler(streams, heartbeat, handler)
+  }
+
+  return route
+}
+
+func sseHandler(streams *streamRegistry, heartbeat time.Duration, handler func(stream *SSEStream)) func(c *gin.Context) {
+  return func(c *gin.Context) {
+    if !streams.open() {
+      RenderError(c, NewError(http.StatusServiceUnavailable, "shutting_down", "server is shutting down"))
+      return
+    }
+    defer streams.release()
+
+    ctx, cancel := streams.streamContext(c.Request.Context())
+    stream := &SSEStream{c: c, ctx: ctx, cancel: cancel}
+    defer stream.finish()
+
+    header := c.Writer.Header()
+    header.Set("Content-Type", sseContentType)
+    header.Set("Cache-Control", "no-cache")
+    header.Set("Connection", "keep-alive")
+    header.Set("X-Accel-Buffering", "no")
+
+    clearWriteDeadline(c.Request)
+
+    c.Status(http.StatusOK)
+    c.Writer.WriteHeaderNow()
+    c.Writer.Flush()
+
+    go stream.heartbeat(heartbeat)
+
+    handler(stream)
+  }
+}
+
+func (s *SSEStream) Context() context.Context {
+  return s.ctx
+}
+
+func (s *SSEStream) Done() <-chan struct{} {
+  return s.ctx.Done()
+}
+
+func (s *SSEStream) Request() *http.Request {
+  return s.c.Request
+}
+
+func (s *SSEStream) Gin() *gin.Context {
+  return s.c
+}
+
+func (s *SSEStream) LastEventID() string {
+  return s.c.GetHeader(lastEventIDHeader)
+}
+
+func (s *SSEStream) Send(message StreamMessage) error {
+  var buf bytes.Buffer
+
+  if message.ID != "" {
+    fmt.Fprintf(&buf, "id: %s\n", message.ID)
+  }
+
+  if message.Event != "" {
+    fmt.Fprintf(&buf, "event: %s\n", message.Event)
+  }
+
+  for _, line := range bytes.Split(message.Data, []byte("\n")) {
+    fmt.Fprintf(&buf, "data: %s\n", line)
+  }
+
+  buf.WriteByte('\n')
+
+  return s.write(buf.Bytes())
+}
+
+func (s *SSEStream) SendJSON(event string, v interface{}) error {
+  data, err := json.Marshal(v)
+  if err != nil {
+    return err
+  }
+
+  return s.Send(StreamMessage{Event: event, Data: data})
+}
+
+func (s *SSEStream) Close() {
+  s.cancel()
+}
+
+func (s *SSEStream) finish() {
+  s.cancel()
+
+  s.mu.Lock()
+  defer s.mu.Unlock()
+}
+
+func (s *SSEStream) write(data []byte) error {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  select {
+  case <-s.ctx.Done():
+    return ErrStreamClosed
+  default:
+  }
+
+  if _, err := s.c.Writer.Write(data); err != nil {
+    s.cancel()
+
+    return err
+  }
+
+  s.c.Writer.Flush()
+
+  return nil
+}
+
+func (s *SSEStream) heartbeat(interval time.Duration) {
+  ticker := time.NewTicker(interval)
+  defer ticker.Stop()
+
+  for {
+    select {
+    case <-s.ctx.Done():
+      return
+    case <-ticker.C:
+      if err := s.write([]byte(": heartbeat\n\n")); err != nil {
+        return
+      }
+    }
+  }
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+  "fmt"
+  "mime"
+  "net/http"
+  "os"
+  "path"
+  "strings"
+  "sync"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  cacheControlHeader    = "Cache-Control"
+  immutableCacheControl = "public, max-age=31536000, immutable"
+  noCacheControl        = "no-cache"
+  precompressedVariants = []struct {
+    algorithm config.CompressionAlgorithm
+    extension string
+  }{
+    {config.CompressionBrotli, ".br"},
+    {config.CompressionGzip, ".gz"},
+  }
+)
+
+type staticFiles struct {
+  mu       sync.RWMutex
+  conf     config.Static
+  fs       http.FileSystem
+  excluded []string
+}
+
+func newStaticFiles(conf config.API) *staticFiles {
+  excluded := []string{conf.PathPrefix}
+  for _, version := range conf.Versions {
+    excluded = append(excluded, version.Prefix)
+  }
+
+  static := &staticFiles{excluded: excluded}
+  if conf.Static.Enabled && conf.Static.Dir != "" {
+    static.mount(conf.Static, http.Dir(conf.Static.Dir))
+  }
+
+  return static
+}
+
+func (r *Router) MountStatic(conf config.Static, fs http.FileSystem) {
+  r.static.mount(conf, fs)
+}
+
+func (s *staticFiles) mount(conf config.Static, fs http.FileSystem) {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  s.conf = conf.WithDefaults()
+  s.fs = fs
+}
+
+func (s *staticFiles) serve(c *gin.Context) bool {
+  s.mu.RLock()
+  conf, fs := s.conf, s.fs
+  s.mu.RUnlock()
+
+  if fs == nil || (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+    return false
+  }
+
+  name, ok := s.resolve(conf, c.Request.URL.Path)
+  if !ok {
+    return false
+  }
+
+  if file, info, err := openFile(fs, name); err == nil {
+    defer file.Close()
+
+    if !info.IsDir() {
+      s.serveFile(c, conf, fs, name, file, info)
+
+      return true
+    }
+
+    name = path.Join(name, conf.Index)
+    if index, indexInfo, err := openFile(fs, name); err == nil {
+      defer index.Close()
+
+      if !indexInfo.IsDir() {
+        s.serveFile(c, conf, fs, name, index, indexInfo)
+
+        return true
+      }
+    }
+  }
+
+  if !conf.SPA || path.Ext(c.Request.URL.Path) != "" {
+    return false
+  }
+
+  index, info, err := openFile(fs, "/"+conf.Index)
+  if err != nil || info.IsDir() {
+    return false
+  }
+  defer index.Close()
+
+  s.serveFile(c, conf, fs, "/"+conf.Index, index, info)
+
+  return true
+}
+
+func (s *staticFiles) resolve(conf config.Static, requestPath string) (string, bool) {
+  requestPath = path.Clean("/" + requestPath)
+
+  for _, prefix := range s.excluded {
+    if prefix != "" && prefix != "/" && (requestPath == prefix || strings.HasPrefix(requestPath, strings.TrimRight(prefix, "/")+"/")) {
+      return "", false
+    }
+  }
+
+  mountPath := strings.TrimRight(conf.MountPath, "/")
+  if mountPath != "" && requestPath != mountPath && !strings.HasPrefix(requestPath, mountPath+"/") {
+    return "", false
+  }
+
+  return path.Clean("/" + strings.TrimPrefix(requestPath, mountPath)), true
+}
+
+func (s *staticFiles) serveFile(c *gin.Context, conf config.Static, fs http.FileSystem, name string, file http.File, info os.FileInfo) {
+  header := c.Writer.Header()
+
+  switch {
+  case path.Base(name) == conf.Index:
+    header.Set(cacheControlHeader, noCacheControl)
+  case isExcludedPath(conf.Immutable, nil, name):
+    header.Set(cacheControlHeader, immutableCacheControl)
+  default:
+    header.Set(cacheControlHeader, fmt.Sprintf("public, max-age=%d", int64(conf.MaxAge.Seconds())))
+  }
+
+  if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+    header.Set("Content-Type", contentType)
+  }
+
+  if conf.Precompressed {
+    header.Add(varyHeader, acceptEncodingHeader)
+
+    acceptEncoding := c.GetHeader(acceptEncodingHeader)
+    for _, variant := range precompressedVariants {
+      if negotiateEncoding(acceptEncoding, []config.CompressionAlgorithm{variant.algorithm}) != variant.algorithm {
+        continue
+      }
+
+      compressed, compressedInfo, err := openFile(fs, name+variant.extension)
+      if err != nil || compressedInfo.IsDir() {
+        continue
+      }
+      defer compressed.Close()
+
+      header.Set(contentEncodingHeader, variant.algorithm.String())
+      http.ServeContent(c.Writer, c.Request, name, compressedInfo.ModTime(), compressed)
+
+      return
+    }
+  }
+
+  http.ServeContent(c.Writer, c.Request, name, info.ModTime(), file)
+}
+
+func openFile(fs http.FileSystem, name string) (http.File, os.FileInfo, error) {
+  file, err := fs.Open(name)
+  if err != nil {
+    return nil, nil, err
+  }
+
+  info, err := file.Stat()
+  if err != nil {
+    file.Close()
+
+    return nil, nil, err
+  }
+
+  return file, info, nil
+}
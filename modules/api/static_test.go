@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Static files", func() {
+	var (
+		router api.Router
+		dir    string
+	)
+
+	writeFile := func(name, content string) {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(full), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(full, []byte(content), 0644)).To(Succeed())
+	}
+
+	perform := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "agora-static")
+		Expect(err).To(BeNil())
+
+		writeFile("index.html", "<html>app</html>")
+		writeFile("robots.txt", "User-agent: *")
+		writeFile("assets/app.123.js", "console.log('app')")
+		writeFile("assets/app.123.js.br", "brotli-bytes")
+		writeFile("docs/index.html", "<html>docs</html>")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Context("when a static directory is configured", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{
+				Port:       8123,
+				PathPrefix: "/api",
+				Static: config.Static{
+					Enabled:       true,
+					Dir:           dir,
+					SPA:           true,
+					MaxAge:        time.Hour,
+					Immutable:     []string{"/assets/*"},
+					Precompressed: true,
+				},
+			})
+
+			controller := api.NewController("/things")
+			controller.Register(api.NewGETRoute("", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"things": true})
+			}))
+			router.Register(controller)
+		})
+
+		It("should serve files with cache headers", func() {
+			rr := perform(http.MethodGet, "/robots.txt", nil)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(Equal("User-agent: *"))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring("text/plain"))
+			Expect(rr.Header().Get("Cache-Control")).To(Equal("public, max-age=3600"))
+			Expect(rr.Header().Get("Last-Modified")).ToNot(BeEmpty())
+		})
+
+		It("should serve precompressed variants of immutable assets", func() {
+			rr := perform(http.MethodGet, "/assets/app.123.js", map[string]string{"Accept-Encoding": "gzip, br"})
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(Equal("brotli-bytes"))
+			Expect(rr.Header().Get("Content-Encoding")).To(Equal("br"))
+			Expect(rr.Header().Get("Content-Type")).To(ContainSubstring("javascript"))
+			Expect(rr.Header().Get("Vary")).To(ContainSubstring("Accept-Encoding"))
+			Expect(rr.Header().Get("Cache-Control")).To(Equal("public, max-age=31536000, immutable"))
+
+			plain := perform(http.MethodGet, "/assets/app.123.js", map[string]string{"Accept-Encoding": "gzip"})
+			Expect(plain.Body.String()).To(Equal("console.log('app')"))
+			Expect(plain.Header().Get("Content-Encoding")).To(BeEmpty())
+		})
+
+		It("should serve directory indexes", func() {
+			rr := perform(http.MethodGet, "/docs/", nil)
+
+			Expect(rr.Code).To(Equal(http.StatusOK))
+			Expect(rr.Body.String()).To(Equal("<html>docs</html>"))
+			Expect(rr.Header().Get("Cache-Control")).To(Equal("no-cache"))
+		})
+
+		It("should fall back to index.html for unknown application paths", func() {
+			for _, path := range []string{"/", "/dashboard/settings"} {
+				rr := perform(http.MethodGet, path, nil)
+
+				Expect(rr.Code).To(Equal(http.StatusOK))
+				Expect(rr.Body.String()).To(Equal("<html>app</html>"))
+				Expect(rr.Header().Get("Content-Type")).To(ContainSubstring("text/html"))
+				Expect(rr.Header().Get("Cache-Control")).To(Equal("no-cache"))
+			}
+		})
+
+		It("should keep API paths, missing assets and unsafe methods as problems", func() {
+			Expect(perform(http.MethodGet, "/api/things", nil).Body.String()).To(Equal(`{"things":true}`))
+
+			for _, rr := range []*httptest.ResponseRecorder{
+				perform(http.MethodGet, "/api/missing", nil),
+				perform(http.MethodGet, "/assets/missing.js", nil),
+				perform(http.MethodPost, "/dashboard", nil),
+			} {
+				Expect(rr.Code).To(Equal(http.StatusNotFound))
+				Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+			}
+		})
+	})
+
+	Context("when a filesystem is mounted on a sub path", func() {
+		BeforeEach(func() {
+			router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+			router.MountStatic(config.Static{MountPath: "/admin", SPA: true}, http.Dir(dir))
+		})
+
+		It("should only serve paths beneath the mount path", func() {
+			Expect(perform(http.MethodGet, "/admin/robots.txt", nil).Body.String()).To(Equal("User-agent: *"))
+			Expect(perform(http.MethodGet, "/admin/users/1", nil).Body.String()).To(Equal("<html>app</html>"))
+			Expect(perform(http.MethodGet, "/admin", nil).Body.String()).To(Equal("<html>app</html>"))
+			Expect(perform(http.MethodGet, "/robots.txt", nil).Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should not escape the mount path", func() {
+			Expect(perform(http.MethodGet, "/admin/../robots.txt", nil).Code).To(Equal(http.StatusNotFound))
+			Expect(perform(http.MethodGet, "/admin/../../etc/passwd", nil).Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
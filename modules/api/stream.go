@@ -0,0 +1,266 @@
+package api
+
+import (
+  "context"
+  "net"
+  "net/http"
+  "net/url"
+  "strings"
+  "sync"
+  "time"
+
+  "github.com/wgentry22/agora/modules/broker"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  defaultStreamHeartbeat    = 15 * time.Second
+  defaultBroadcastQueueSize = 16
+)
+
+type StreamMessage struct {
+  ID    string
+  Event string
+  Data  []byte
+}
+
+type connContextKey struct{}
+
+type streamRegistry struct {
+  mu           sync.Mutex
+  wg           sync.WaitGroup
+  closing      chan struct{}
+  closeOnce    sync.Once
+  allowOrigins []string
+}
+
+func newStreamRegistry(conf config.WebSocket) *streamRegistry {
+  return &streamRegistry{closing: make(chan struct{}), allowOrigins: conf.AllowOrigins}
+}
+
+func (s *streamRegistry) open() bool {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  select {
+  case <-s.closing:
+    return false
+  default:
+  }
+
+  s.wg.Add(1)
+
+  return true
+}
+
+func (s *streamRegistry) release() {
+  s.wg.Done()
+}
+
+func (s *streamRegistry) allowsOrigin(req *http.Request) bool {
+  origin := req.Header.Get("Origin")
+  if origin == "" {
+    return true
+  }
+
+  for _, allowed := range s.allowOrigins {
+    if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+      return true
+    }
+  }
+
+  parsed, err := url.Parse(origin)
+  if err != nil {
+    return false
+  }
+
+  return strings.EqualFold(parsed.Host, req.Host)
+}
+
+func (s *streamRegistry) close(ctx context.Context) error {
+  s.closeOnce.Do(func() {
+    s.mu.Lock()
+    close(s.closing)
+    s.mu.Unlock()
+  })
+
+  finished := make(chan struct{})
+  go func() {
+    s.wg.Wait()
+    close(finished)
+  }()
+
+  select {
+  case <-finished:
+    return nil
+  case <-ctx.Done():
+    return ctx.Err()
+  }
+}
+
+func (s *streamRegistry) streamContext(parent context.Context) (context.Context, context.CancelFunc) {
+  ctx, cancel := context.WithCancel(parent)
+
+  go func() {
+    select {
+    case <-s.closing:
+      cancel()
+    case <-ctx.Done():
+    }
+  }()
+
+  return ctx, cancel
+}
+
+func withConn(ctx context.Context, conn net.Conn) context.Context {
+  return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+func clearWriteDeadline(req *http.Request) {
+  if conn, ok := req.Context().Value(connContextKey{}).(net.Conn); ok {
+    _ = conn.SetWriteDeadline(time.Time{})
+  }
+}
+
+func (r *Router) CloseStreams(ctx context.Context) error {
+  return r.streams.close(ctx)
+}
+
+func (r Route) WithHeartbeat(interval time.Duration) Route {
+  r.heartbeat = interval
+
+  return r
+}
+
+func heartbeatInterval(route Route) time.Duration {
+  if route.heartbeat > 0 {
+    return route.heartbeat
+  }
+
+  return defaultStreamHeartbeat
+}
+
+type Broadcaster struct {
+  mu          sync.RWMutex
+  subscribers map[chan StreamMessage]struct{}
+  closed      bool
+}
+
+func NewBroadcaster() *Broadcaster {
+  return &Broadcaster{subscribers: make(map[chan StreamMessage]struct{})}
+}
+
+func NewTopicBroadcaster(consumer broker.Consumer, topic string) *Broadcaster {
+  broadcaster := NewBroadcaster()
+
+  consumer.RegisterHandler(topic, func(payload []byte) error {
+    broadcaster.Publish(StreamMessage{Event: topic, Data: payload})
+
+    return nil
+  })
+
+  return broadcaster
+}
+
+func (b *Broadcaster) Publish(message StreamMessage) {
+  b.mu.RLock()
+  defer b.mu.RUnlock()
+
+  for subscriber := range b.subscribers {
+    select {
+    case subscriber <- message:
+    default:
+    }
+  }
+}
+
+func (b *Broadcaster) Subscribe() (<-chan StreamMessage, func()) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  subscriber := make(chan StreamMessage, defaultBroadcastQueueSize)
+  if b.closed {
+    close(subscriber)
+
+    return subscriber, func() {}
+  }
+
+  b.subscribers[subscriber] = struct{}{}
+
+  var once sync.Once
+
+  return subscriber, func() {
+    once.Do(func() {
+      b.mu.Lock()
+      defer b.mu.Unlock()
+
+      if _, ok := b.subscribers[subscriber]; ok {
+        delete(b.subscribers, subscriber)
+        close(subscriber)
+      }
+    })
+  }
+}
+
+func (b *Broadcaster) Subscribers() int {
+  b.mu.RLock()
+  defer b.mu.RUnlock()
+
+  return len(b.subscribers)
+}
+
+func (b *Broadcaster) Close() {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+
+  b.closed = true
+  for subscriber := range b.subscribers {
+    delete(b.subscribers, subscriber)
+    close(subscriber)
+  }
+}
+
+func (b *Broadcaster) SSEHandler() func(stream *SSEStream) {
+  return func(stream *SSEStream) {
+    messages, unsubscribe := b.Subscribe()
+    defer unsubscribe()
+
+    for {
+      select {
+      case <-stream.Done():
+        return
+      case message, ok := <-messages:
+        if !ok {
+          return
+        }
+
+        if err := stream.Send(message); err != nil {
+          return
+        }
+      }
+    }
+  }
+}
+
+func (b *Broadcaster) WebSocketHandler() func(conn *WebSocketConn) {
+  return func(conn *WebSocketConn) {
+    messages, unsubscribe := b.Subscribe()
+    defer unsubscribe()
+
+    for {
+      select {
+      case <-conn.Done():
+        return
+      case <-conn.Messages():
+      case message, ok := <-messages:
+        if !ok {
+          return
+        }
+
+        if err := conn.WriteMessage(TextMessage, message.Data); err != nil {
+          return
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,422 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/broker"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type fakeConsumer struct {
+	handlers map[string]broker.EventHandler
+}
+
+func (f *fakeConsumer) Start() {}
+
+func (f *fakeConsumer) RegisterHandler(topic string, handler broker.EventHandler) {
+	f.handlers[topic] = handler
+}
+
+func (f *fakeConsumer) Errors() <-chan error {
+	return nil
+}
+
+type wsClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocket(url, origin string) (*wsClient, *http.Response) {
+	return dialWebSocketPath(url, "/api/live/socket", origin)
+}
+
+func dialWebSocketPath(url, path, origin string) (*wsClient, *http.Response) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	Expect(err).To(BeNil())
+
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+
+	req, err := http.NewRequest(http.MethodGet, url+path, nil)
+	Expect(err).To(BeNil())
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", base64.StdEncoding.EncodeToString(key))
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	Expect(req.Write(conn)).To(Succeed())
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, req)
+	Expect(err).To(BeNil())
+
+	return &wsClient{conn: conn, reader: reader}, res
+}
+
+func (w *wsClient) send(opcode byte, payload []byte) {
+	w.sendFrame(true, opcode, payload, true)
+}
+
+func (w *wsClient) sendFrame(fin bool, opcode byte, payload []byte, masked bool) {
+	frame := []byte{opcode, byte(len(payload))}
+	if fin {
+		frame[0] |= 0x80
+	}
+
+	if !masked {
+		_, err := w.conn.Write(append(frame, payload...))
+		Expect(err).To(BeNil())
+
+		return
+	}
+
+	frame[1] |= 0x80
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.conn.Write(frame)
+	Expect(err).To(BeNil())
+}
+
+func (w *wsClient) expectClose(code int) []byte {
+	opcode, payload := w.readData()
+	Expect(opcode).To(Equal(byte(0x8)))
+	Expect(binary.BigEndian.Uint16(payload)).To(Equal(uint16(code)))
+
+	return payload
+}
+
+func (w *wsClient) read() (byte, []byte) {
+	_ = w.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(w.reader, header)
+	Expect(err).To(BeNil())
+
+	length := int(header[1] & 0x7f)
+	if length == 126 {
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(w.reader, extended)
+		Expect(err).To(BeNil())
+		length = int(binary.BigEndian.Uint16(extended))
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(w.reader, payload)
+	Expect(err).To(BeNil())
+
+	return header[0] & 0x0f, payload
+}
+
+func (w *wsClient) readData() (byte, []byte) {
+	for {
+		opcode, payload := w.read()
+		if opcode != 0x9 {
+			return opcode, payload
+		}
+	}
+}
+
+var _ = Describe("Streaming routes", func() {
+	var (
+		router      api.Router
+		server      *httptest.Server
+		consumer    *fakeConsumer
+		broadcaster *api.Broadcaster
+	)
+
+	BeforeEach(func() {
+		consumer = &fakeConsumer{handlers: map[string]broker.EventHandler{}}
+		broadcaster = api.NewTopicBroadcaster(consumer, "orders")
+
+		router = api.NewRouter(config.API{
+			Port:       8123,
+			PathPrefix: "/api",
+			Timeout:    config.TimeoutOptions{Write: 100 * time.Millisecond},
+			WebSocket:  config.WebSocket{AllowOrigins: []string{"https://app.example.com"}},
+		})
+
+		controller := api.NewController("/live")
+		controller.Register(api.NewSSERoute("/events", func(stream *api.SSEStream) {
+			Expect(stream.Send(api.StreamMessage{ID: stream.LastEventID() + "1", Event: "greeting", Data: []byte("hello\nworld")})).To(Succeed())
+
+			<-stream.Done()
+		}).WithHeartbeat(20 * time.Millisecond))
+		controller.Register(api.NewSSERoute("/orders", broadcaster.SSEHandler()))
+		controller.Register(api.NewWebSocketRoute("/socket", func(conn *api.WebSocketConn) {
+			for {
+				select {
+				case <-conn.Done():
+					return
+				case message := <-conn.Messages():
+					Expect(conn.WriteMessage(message.Type, append([]byte("echo: "), message.Data...))).To(Succeed())
+				}
+			}
+		}).WithHeartbeat(20 * time.Millisecond))
+		controller.Register(api.NewWebSocketRoute("/stalled", func(conn *api.WebSocketConn) {
+			<-conn.Done()
+		}).WithHeartbeat(20 * time.Millisecond))
+		controller.Register(api.NewWebSocketRoute("/farewell", func(conn *api.WebSocketConn) {
+			_ = conn.Close(api.CloseNormalClosure, strings.Repeat("é", 100))
+		}).WithHeartbeat(20 * time.Millisecond))
+		controller.Register(api.NewGETRoute("/slow", func(c *gin.Context) {
+			time.Sleep(200 * time.Millisecond)
+			c.String(http.StatusOK, "too late")
+		}))
+		router.Register(controller)
+
+		server = httptest.NewServer(router.Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should stream server-sent events with heartbeats", func() {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/live/events", nil)
+		Expect(err).To(BeNil())
+		req.Header.Set("Last-Event-ID", "4")
+
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		defer res.Body.Close()
+
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+		Expect(res.Header.Get("Cache-Control")).To(Equal("no-cache"))
+
+		reader := bufio.NewReader(res.Body)
+		lines := make([]string, 0)
+		for len(lines) < 6 {
+			line, err := reader.ReadString('\n')
+			Expect(err).To(BeNil())
+			lines = append(lines, line)
+		}
+
+		Expect(lines[:5]).To(Equal([]string{"id: 41\n", "event: greeting\n", "data: hello\n", "data: world\n", "\n"}))
+		Expect(lines[5]).To(Equal(": heartbeat\n"))
+	})
+
+	It("should bridge broker events to SSE clients", func() {
+		res, err := http.Get(server.URL + "/api/live/orders")
+		Expect(err).To(BeNil())
+		defer res.Body.Close()
+
+		Eventually(broadcaster.Subscribers).Should(Equal(1))
+		Expect(consumer.handlers["orders"]([]byte(`{"id":1}`))).To(Succeed())
+
+		reader := bufio.NewReader(res.Body)
+		event, err := reader.ReadString('\n')
+		Expect(err).To(BeNil())
+		Expect(event).To(Equal("event: orders\n"))
+
+		data, err := reader.ReadString('\n')
+		Expect(err).To(BeNil())
+		Expect(data).To(Equal("data: {\"id\":1}\n"))
+
+		res.Body.Close()
+		Eventually(broadcaster.Subscribers).Should(Equal(0))
+	})
+
+	It("should end streams when the router closes them", func() {
+		res, err := http.Get(server.URL + "/api/live/events")
+		Expect(err).To(BeNil())
+		defer res.Body.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		Expect(router.CloseStreams(ctx)).To(Succeed())
+
+		_, err = io.Copy(ioutil.Discard, res.Body)
+		Expect(err).To(BeNil())
+
+		late, err := http.Get(server.URL + "/api/live/events")
+		Expect(err).To(BeNil())
+		defer late.Body.Close()
+		Expect(late.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should upgrade and echo WebSocket messages", func() {
+		client, res := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		Expect(res.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+		Expect(res.Header.Get("Sec-WebSocket-Accept")).ToNot(BeEmpty())
+
+		client.send(0x1, []byte("ping?"))
+		opcode, payload := client.readData()
+		Expect(opcode).To(Equal(byte(0x1)))
+		Expect(string(payload)).To(Equal("echo: ping?"))
+
+		opcode, _ = client.read()
+		Expect(opcode).To(Equal(byte(0x9)))
+
+		client.send(0x8, []byte{0x03, 0xe8})
+		opcode, payload = client.readData()
+		Expect(opcode).To(Equal(byte(0x8)))
+		Expect(binary.BigEndian.Uint16(payload)).To(Equal(uint16(api.CloseNormalClosure)))
+	})
+
+	It("should reassemble fragmented messages and answer pings in between", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		client.sendFrame(false, 0x1, []byte("hel"), true)
+		client.send(0x9, []byte("mid"))
+
+		opcode, payload := client.readData()
+		Expect(opcode).To(Equal(byte(0xa)))
+		Expect(string(payload)).To(Equal("mid"))
+
+		client.sendFrame(true, 0x0, []byte("lo"), true)
+
+		opcode, payload = client.readData()
+		Expect(opcode).To(Equal(byte(0x1)))
+		Expect(string(payload)).To(Equal("echo: hello"))
+	})
+
+	It("should close with a protocol error for unexpected continuation frames", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		client.sendFrame(true, 0x0, []byte("orphan"), true)
+		client.expectClose(api.CloseProtocolError)
+	})
+
+	It("should close with a protocol error for unmasked frames", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		client.sendFrame(true, 0x1, []byte("plain"), false)
+		client.expectClose(api.CloseProtocolError)
+	})
+
+	It("should close when a frame exceeds the maximum message size", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		header := []byte{0x82, 0x80 | 127, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(header[2:], uint64(api.MaxWebSocketMessageSize+1))
+		_, err := client.conn.Write(header)
+		Expect(err).To(BeNil())
+
+		client.expectClose(api.CloseMessageTooBig)
+	})
+
+	It("should close when a text message is not valid UTF-8", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		client.send(0x1, []byte{0xff, 0xfe})
+		client.expectClose(api.CloseInvalidFramePayloadData)
+	})
+
+	It("should not echo reserved close codes", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		client.send(0x8, []byte{0x03, 0xed})
+		client.expectClose(api.CloseProtocolError)
+	})
+
+	It("should cap close reasons to fit a control frame", func() {
+		client, _ := dialWebSocketPath(server.URL, "/api/live/farewell", "")
+		defer client.conn.Close()
+
+		payload := client.expectClose(api.CloseNormalClosure)
+		Expect(len(payload)).To(BeNumerically("<=", 125))
+		Expect(utf8.Valid(payload[2:])).To(BeTrue())
+	})
+
+	It("should close when the handler stops draining messages", func() {
+		client, _ := dialWebSocketPath(server.URL, "/api/live/stalled", "")
+		defer client.conn.Close()
+
+		for i := 0; i < 17; i++ {
+			client.send(0x2, []byte{byte(i)})
+		}
+
+		client.expectClose(api.CloseInternalError)
+	})
+
+	It("should close WebSockets with going away on shutdown", func() {
+		client, _ := dialWebSocket(server.URL, "")
+		defer client.conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		Expect(router.CloseStreams(ctx)).To(Succeed())
+
+		opcode, payload := client.readData()
+		Expect(opcode).To(Equal(byte(0x8)))
+		Expect(binary.BigEndian.Uint16(payload)).To(Equal(uint16(api.CloseGoingAway)))
+	})
+
+	It("should only accept WebSockets from the same or allowed origins", func() {
+		rejected, res := dialWebSocket(server.URL, "https://evil.example.com")
+		defer rejected.conn.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusForbidden))
+
+		same, res := dialWebSocket(server.URL, server.URL)
+		defer same.conn.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+
+		allowed, res := dialWebSocket(server.URL, "https://app.example.com")
+		defer allowed.conn.Close()
+		Expect(res.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+	})
+
+	It("should keep streams open past the server write timeout", func() {
+		timed := httptest.NewUnstartedServer(nil)
+		timed.Config = router.Server()
+		timed.Start()
+		defer timed.Close()
+
+		Expect(timed.Config.WriteTimeout).To(Equal(100 * time.Millisecond))
+
+		_, err := http.Get(timed.URL + "/api/live/slow")
+		Expect(err).ToNot(BeNil())
+
+		res, err := http.Get(timed.URL + "/api/live/events")
+		Expect(err).To(BeNil())
+		defer res.Body.Close()
+
+		reader := bufio.NewReader(res.Body)
+		for started := time.Now(); time.Since(started) < 300*time.Millisecond; {
+			_, err := reader.ReadString('\n')
+			Expect(err).To(BeNil())
+		}
+	})
+
+	It("should require an upgrade for plain requests", func() {
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/live/socket", nil))
+
+		Expect(rr.Code).To(Equal(http.StatusUpgradeRequired))
+		Expect(rr.Header().Get("Upgrade")).To(Equal("websocket"))
+	})
+})
@@ -0,0 +1,150 @@
+package api
+
+import (
+  "crypto/tls"
+  "crypto/x509"
+  "errors"
+  "io/ioutil"
+  "sync"
+
+  "github.com/hashicorp/errwrap"
+  "github.com/wgentry22/agora/modules/logg"
+  "github.com/wgentry22/agora/modules/watcher"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  ErrFailedToLoadCertificate = errors.New("failed to load tls certificate")
+  ErrFailedToLoadClientCA    = errors.New("failed to load tls client certificate authority")
+  ErrNoClientCACertificates  = errors.New("no certificates found in tls client certificate authority")
+)
+
+type certificateReloader struct {
+  conf      config.TLS
+  base      *tls.Config
+  mu        sync.RWMutex
+  cert      *tls.Certificate
+  clientCAs *x509.CertPool
+}
+
+func newCertificateReloader(conf config.TLS) (*certificateReloader, error) {
+  reloader := &certificateReloader{conf: conf}
+
+  if err := reloader.reload(); err != nil {
+    return nil, err
+  }
+
+  files := []string{conf.CertFile, conf.KeyFile}
+  if conf.IsMutual() {
+    files = append(files, conf.ClientCAFile)
+  }
+
+  fw := watcher.NewFileWatcher(files...)
+
+  errc := fw.Watch(make(chan error, 1))
+  select {
+  case err := <-errc:
+    return nil, err
+  default:
+  }
+
+  go reloader.watch(fw, errc)
+
+  return reloader, nil
+}
+
+func (c *certificateReloader) watch(fw watcher.FileWatcher, errc <-chan error) {
+  for {
+    select {
+    case file := <-fw.Changes():
+      if err := c.reload(); err != nil {
+        logg.Root().
+          WithField("file", file).
+          WithError(err).
+          Warn("Failed to reload tls certificates, serving the last loaded certificates")
+      }
+    case err := <-errc:
+      logg.Root().WithError(err).Warn("Failed to watch tls certificates")
+    }
+  }
+}
+
+func (c *certificateReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+  c.mu.RLock()
+  defer c.mu.RUnlock()
+
+  return c.cert, nil
+}
+
+func (c *certificateReloader) GetConfigForClient(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+  c.mu.RLock()
+  defer c.mu.RUnlock()
+
+  clientConfig := c.base.Clone()
+  clientConfig.ClientCAs = c.clientCAs
+
+  return clientConfig, nil
+}
+
+func (c *certificateReloader) reload() error {
+  cert, err := tls.LoadX509KeyPair(c.conf.CertFile, c.conf.KeyFile)
+  if err != nil {
+    return errwrap.Wrap(ErrFailedToLoadCertificate, err)
+  }
+
+  var clientCAs *x509.CertPool
+  if c.conf.IsMutual() {
+    if clientCAs, err = loadCertPool(c.conf.ClientCAFile); err != nil {
+      return err
+    }
+  }
+
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  c.cert = &cert
+  c.clientCAs = clientCAs
+
+  return nil
+}
+
+func NewTLSConfig(conf config.TLS) (*tls.Config, error) {
+  reloader, err := newCertificateReloader(conf)
+  if err != nil {
+    return nil, err
+  }
+
+  tlsConfig := &tls.Config{
+    MinVersion:     conf.MinVersion,
+    CipherSuites:   conf.CipherSuites,
+    GetCertificate: reloader.GetCertificate,
+  }
+
+  if conf.IsMutual() {
+    if conf.RequireClientCert {
+      tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+    } else {
+      tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+    }
+
+    reloader.base = tlsConfig.Clone()
+    tlsConfig.ClientCAs = reloader.clientCAs
+    tlsConfig.GetConfigForClient = reloader.GetConfigForClient
+  }
+
+  return tlsConfig, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+  contents, err := ioutil.ReadFile(file)
+  if err != nil {
+    return nil, errwrap.Wrap(ErrFailedToLoadClientCA, err)
+  }
+
+  pool := x509.NewCertPool()
+  if !pool.AppendCertsFromPEM(contents) {
+    return nil, ErrNoClientCACertificates
+  }
+
+  return pool, nil
+}
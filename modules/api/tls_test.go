@@ -0,0 +1,248 @@
+package api_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCertificate(commonName string, serial int64, parent *testCertificate) testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).To(BeNil())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	Expect(err).To(BeNil())
+
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).To(BeNil())
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	Expect(err).To(BeNil())
+
+	return testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (t testCertificate) keyPair() tls.Certificate {
+	pair, err := tls.X509KeyPair(t.certPEM, t.keyPEM)
+	Expect(err).To(BeNil())
+
+	return pair
+}
+
+var _ = Describe("TLS", func() {
+	var (
+		dir      string
+		ca       testCertificate
+		server   *http.Server
+		listener net.Listener
+		certFile string
+		keyFile  string
+		caFile   string
+	)
+
+	write := func(name string, contents []byte) string {
+		file := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(file, contents, 0600)).To(Succeed())
+
+		return file
+	}
+
+	serve := func(conf config.TLS) {
+		router := api.NewRouter(config.API{Port: 8123, PathPrefix: "/api", TLS: conf})
+
+		controller := api.NewController("/hello")
+		controller.Register(api.NewGETRoute("", func(c *gin.Context) {
+			c.JSON(http.StatusOK, map[string]bool{"mutual": len(c.Request.TLS.VerifiedChains) > 0})
+		}))
+		router.Register(controller)
+
+		server = router.Server()
+		Expect(server.TLSConfig).NotTo(BeNil())
+
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).To(BeNil())
+
+		go func() {
+			_ = server.ServeTLS(listener, "", "")
+		}()
+	}
+
+	client := func(certificates ...tls.Certificate) *http.Client {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+
+		return &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certificates},
+			},
+		}
+	}
+
+	url := func() string {
+		return "https://" + listener.Addr().String() + "/api/hello"
+	}
+
+	servedSerial := func() int64 {
+		res, err := client().Get(url())
+		if err != nil {
+			return 0
+		}
+		defer res.Body.Close()
+
+		return res.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "agora-tls")
+		Expect(err).To(BeNil())
+
+		ca = newTestCertificate("agora-ca", 1, nil)
+		leaf := newTestCertificate("localhost", 2, &ca)
+
+		caFile = write("ca.pem", ca.certPEM)
+		certFile = write("cert.pem", leaf.certPEM)
+		keyFile = write("key.pem", leaf.keyPEM)
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			_ = server.Close()
+		}
+
+		_ = os.RemoveAll(dir)
+	})
+
+	It("should serve requests over TLS", func() {
+		serve(config.TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS12})
+
+		res, err := client().Get(url())
+		Expect(err).To(BeNil())
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.TLS.Version).To(BeNumerically(">=", tls.VersionTLS12))
+	})
+
+	It("should pick up a replaced certificate without restarting", func() {
+		serve(config.TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS12})
+
+		Expect(servedSerial()).To(Equal(int64(2)))
+
+		replacement := newTestCertificate("localhost", 3, &ca)
+		write("cert.pem", replacement.certPEM)
+		write("key.pem", replacement.keyPEM)
+
+		Eventually(servedSerial).Should(Equal(int64(3)))
+	})
+
+	It("should keep serving the last good certificate when a reload fails", func() {
+		serve(config.TLS{CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS12})
+
+		replacement := newTestCertificate("localhost", 3, &ca)
+		write("cert.pem", replacement.certPEM)
+
+		Consistently(servedSerial).Should(Equal(int64(2)))
+
+		write("key.pem", replacement.keyPEM)
+
+		Eventually(servedSerial).Should(Equal(int64(3)))
+	})
+
+	Context("when a client certificate is required", func() {
+		BeforeEach(func() {
+			serve(config.TLS{
+				CertFile:          certFile,
+				KeyFile:           keyFile,
+				MinVersion:        tls.VersionTLS12,
+				ClientCAFile:      caFile,
+				RequireClientCert: true,
+			})
+		})
+
+		It("should reject clients without a certificate", func() {
+			_, err := client().Get(url())
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should accept clients presenting a certificate signed by the client CA", func() {
+			clientCert := newTestCertificate("service-a", 4, &ca)
+
+			res, err := client(clientCert.keyPair()).Get(url())
+			Expect(err).To(BeNil())
+			Expect(res.StatusCode).To(Equal(http.StatusOK))
+
+			body, err := ioutil.ReadAll(res.Body)
+			Expect(err).To(BeNil())
+			Expect(string(body)).To(Equal(`{"mutual":true}`))
+		})
+
+		It("should trust a rotated client CA without restarting", func() {
+			rotated := newTestCertificate("agora-ca-2", 5, nil)
+			clientCert := newTestCertificate("service-b", 6, &rotated)
+
+			_, err := client(clientCert.keyPair()).Get(url())
+			Expect(err).NotTo(BeNil())
+
+			write("ca.pem", rotated.certPEM)
+
+			Eventually(func() error {
+				_, err := client(clientCert.keyPair()).Get(url())
+
+				return err
+			}).Should(Succeed())
+		})
+	})
+
+	It("should panic when the certificate cannot be loaded", func() {
+		router := api.NewRouter(config.API{Port: 8123, TLS: config.TLS{CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile}})
+
+		Expect(func() { router.Server() }).To(Panic())
+	})
+})
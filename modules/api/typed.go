@@ -0,0 +1,258 @@
+package api
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "reflect"
+  "strings"
+
+  "github.com/gin-gonic/gin"
+  "github.com/go-playground/validator/v10"
+)
+
+var (
+  ErrInvalidTypedHandler = func(reason string) error {
+    return fmt.Errorf("typed handler must be `func(context.Context, Req) (Resp, error)`: %s", reason)
+  }
+  contextType    = reflect.TypeOf((*context.Context)(nil)).Elem()
+  ginContextType = reflect.TypeOf((*gin.Context)(nil))
+  errorType      = reflect.TypeOf((*error)(nil)).Elem()
+  validate       = newValidator()
+)
+
+type StatusCoder interface {
+  StatusCode() int
+}
+
+func newValidator() *validator.Validate {
+  v := validator.New()
+
+  v.RegisterTagNameFunc(func(field reflect.StructField) string {
+    for _, tag := range []string{"json", "form", "uri"} {
+      name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+      if name != "" && name != "-" {
+        return name
+      }
+    }
+
+    return field.Name
+  })
+
+  return v
+}
+
+func NewTypedGETRoute(uri string, handler interface{}) Route {
+  return newTypedRoute(http.MethodGet, uri, handler)
+}
+
+func NewTypedPOSTRoute(uri string, handler interface{}) Route {
+  return newTypedRoute(http.MethodPost, uri, handler)
+}
+
+func NewTypedPUTRoute(uri string, handler interface{}) Route {
+  return newTypedRoute(http.MethodPut, uri, handler)
+}
+
+func NewTypedPATCHRoute(uri string, handler interface{}) Route {
+  return newTypedRoute(http.MethodPatch, uri, handler)
+}
+
+func NewTypedDELETERoute(uri string, handler interface{}) Route {
+  return newTypedRoute(http.MethodDelete, uri, handler)
+}
+
+func newTypedRoute(method, uri string, handler interface{}) Route {
+  route := newRoute(method, uri, TypedHandler(method, handler))
+
+  fnType := reflect.TypeOf(handler)
+  route.requestType = fnType.In(1)
+  route.responses = map[int]reflect.Type{typedSuccessStatus(method): fnType.Out(0)}
+
+  return route
+}
+
+func typedSuccessStatus(method string) int {
+  if method == http.MethodPost {
+    return http.StatusCreated
+  }
+
+  return http.StatusOK
+}
+
+func TypedHandler(method string, handler interface{}) func(c *gin.Context) {
+  fn := reflect.ValueOf(handler)
+  reqType := typedRequestType(fn.Type())
+
+  successStatus := typedSuccessStatus(method)
+
+  return func(c *gin.Context) {
+    req, err := bindRequest(c, reqType)
+    if err != nil {
+      RenderError(c, err)
+
+      return
+    }
+
+    var ctx reflect.Value
+    if fn.Type().In(0) == ginContextType {
+      ctx = reflect.ValueOf(c)
+    } else {
+      ctx = reflect.ValueOf(c.Request.Context())
+    }
+
+    out := fn.Call([]reflect.Value{ctx, req})
+
+    if errOut := out[1].Interface(); errOut != nil {
+      RenderError(c, errOut.(error))
+
+      return
+    }
+
+    if isNilValue(out[0]) {
+      c.Status(http.StatusNoContent)
+
+      return
+    }
+
+    resp := out[0].Interface()
+
+    status := successStatus
+    if coder, ok := resp.(StatusCoder); ok {
+      status = coder.StatusCode()
+    }
+
+    if status == http.StatusNoContent {
+      c.Status(status)
+
+      return
+    }
+
+    c.JSON(status, resp)
+  }
+}
+
+func typedRequestType(fnType reflect.Type) reflect.Type {
+  if fnType.Kind() != reflect.Func {
+    panic(ErrInvalidTypedHandler("not a function"))
+  }
+
+  if fnType.NumIn() != 2 || fnType.NumOut() != 2 {
+    panic(ErrInvalidTypedHandler("expected two arguments and two return values"))
+  }
+
+  if fnType.In(0) != contextType && fnType.In(0) != ginContextType {
+    panic(ErrInvalidTypedHandler("first argument must be a context.Context or *gin.Context"))
+  }
+
+  reqType := fnType.In(1)
+  if reqType.Kind() != reflect.Struct && !(reqType.Kind() == reflect.Ptr && reqType.Elem().Kind() == reflect.Struct) {
+    panic(ErrInvalidTypedHandler("request must be a struct or pointer to a struct"))
+  }
+
+  if fnType.Out(1) != errorType {
+    panic(ErrInvalidTypedHandler("second return value must be an error"))
+  }
+
+  return reqType
+}
+
+func bindRequest(c *gin.Context, reqType reflect.Type) (reflect.Value, error) {
+  structType := reqType
+  if reqType.Kind() == reflect.Ptr {
+    structType = reqType.Elem()
+  }
+
+  req := reflect.New(structType)
+
+  if err := decodeJSON(c, req.Interface()); err != nil {
+    return req, err
+  }
+
+  if len(c.Request.URL.RawQuery) > 0 {
+    if err := c.ShouldBindQuery(req.Interface()); err != nil {
+      return req, ErrBadRequest("invalid query parameters").WithCause(err)
+    }
+  }
+
+  if len(c.Params) > 0 {
+    if err := c.ShouldBindUri(req.Interface()); err != nil {
+      return req, ErrBadRequest("invalid path parameters").WithCause(err)
+    }
+  }
+
+  if err := Validate(req.Interface()); err != nil {
+    return req, err
+  }
+
+  if reqType.Kind() == reflect.Ptr {
+    return req, nil
+  }
+
+  return req.Elem(), nil
+}
+
+func BindJSON(c *gin.Context, dest interface{}) error {
+  if err := decodeJSON(c, dest); err != nil {
+    return err
+  }
+
+  return Validate(dest)
+}
+
+func decodeJSON(c *gin.Context, dest interface{}) error {
+  if c.Request.Body != nil && c.Request.ContentLength != 0 {
+    if err := json.NewDecoder(c.Request.Body).Decode(dest); err != nil && !errors.Is(err, io.EOF) {
+      if errors.Is(err, ErrRequestBodyTooLarge) {
+        return AsError(err)
+      }
+
+      return ErrBadRequest("request body is not valid JSON").WithCause(err)
+    }
+  }
+
+  return nil
+}
+
+func Validate(v interface{}) error {
+  if err := validate.Struct(v); err != nil {
+    var validationErrs validator.ValidationErrors
+    if errors.As(err, &validationErrs) {
+      return ErrValidation(fieldErrors(validationErrs)).WithCause(err)
+    }
+
+    return ErrBadRequest(err.Error()).WithCause(err)
+  }
+
+  return nil
+}
+
+func fieldErrors(errs validator.ValidationErrors) []FieldError {
+  fields := make([]FieldError, len(errs))
+
+  for i, fe := range errs {
+    message := fmt.Sprintf("failed on the `%s` rule", fe.Tag())
+    if fe.Param() != "" {
+      message = fmt.Sprintf("failed on the `%s=%s` rule", fe.Tag(), fe.Param())
+    }
+
+    fields[i] = FieldError{
+      Field:   fe.Field(),
+      Message: message,
+    }
+  }
+
+  return fields
+}
+
+func isNilValue(v reflect.Value) bool {
+  switch v.Kind() {
+  case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+    return v.IsNil()
+  default:
+    return false
+  }
+}
@@ -0,0 +1,128 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type createWidgetRequest struct {
+	Owner  string `uri:"owner" validate:"required"`
+	DryRun bool   `form:"dryRun"`
+	Name   string `json:"name" validate:"required,min=3"`
+	Count  int    `json:"count" validate:"gte=1"`
+}
+
+type widgetResponse struct {
+	Owner  string `json:"owner"`
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	DryRun bool   `json:"dryRun"`
+}
+
+var _ = Describe("Typed Routes", func() {
+	var (
+		ts *httptest.Server
+	)
+
+	BeforeEach(func() {
+		typedController := api.NewController("/owners")
+		typedController.Register(api.NewTypedPOSTRoute("/:owner/widgets", func(ctx context.Context, req createWidgetRequest) (*widgetResponse, error) {
+			if req.Name == "taken" {
+				return nil, api.ErrConflict("widget name is taken")
+			}
+
+			if req.Name == "boom" {
+				return nil, fmt.Errorf("database exploded")
+			}
+
+			return &widgetResponse{req.Owner, req.Name, req.Count, req.DryRun}, nil
+		}))
+
+		router := api.NewRouter(config.API{PathPrefix: "/api"})
+		router.Register(typedController)
+
+		ts = httptest.NewServer(router.Handler())
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	post := func(query, body string) (*http.Response, map[string]interface{}) {
+		res, err := http.Post(fmt.Sprintf("%s/api/owners/alice/widgets%s", ts.URL, query), "application/json", strings.NewReader(body))
+		Expect(err).To(BeNil())
+
+		var decoded map[string]interface{}
+		Expect(json.NewDecoder(res.Body).Decode(&decoded)).To(Succeed())
+
+		return res, decoded
+	}
+
+	It("should bind path, query and body and serialize the response", func() {
+		res, body := post("?dryRun=true", `{"name":"gizmo","count":2}`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusCreated))
+		Expect(body).To(Equal(map[string]interface{}{
+			"owner":  "alice",
+			"name":   "gizmo",
+			"count":  float64(2),
+			"dryRun": true,
+		}))
+	})
+
+	It("should not let the body override path parameters", func() {
+		res, body := post("", `{"Owner":"mallory","name":"gizmo","count":1}`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusCreated))
+		Expect(body["owner"]).To(Equal("alice"))
+	})
+
+	It("should reject malformed bodies", func() {
+		res, body := post("", `{"name":`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(body["code"]).To(Equal("bad_request"))
+	})
+
+	It("should report field errors when validation fails", func() {
+		res, body := post("", `{"name":"ab","count":0}`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+		Expect(body["code"]).To(Equal("validation_failed"))
+		Expect(body["fields"]).To(ConsistOf(
+			HaveKeyWithValue("field", "name"),
+			HaveKeyWithValue("field", "count"),
+		))
+	})
+
+	It("should map api.Error to its status", func() {
+		res, body := post("", `{"name":"taken","count":1}`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusConflict))
+		Expect(body["code"]).To(Equal("conflict"))
+		Expect(body["detail"]).To(Equal("widget name is taken"))
+	})
+
+	It("should hide unexpected errors behind a 500", func() {
+		res, body := post("", `{"name":"boom","count":1}`)
+
+		Expect(res.StatusCode).To(Equal(http.StatusInternalServerError))
+		Expect(body["code"]).To(Equal("internal_error"))
+		Expect(body["detail"]).ToNot(ContainSubstring("database"))
+	})
+
+	It("should panic when the handler has the wrong shape", func() {
+		Expect(func() {
+			api.NewTypedGETRoute("/", func(req createWidgetRequest) error { return nil })
+		}).To(Panic())
+	})
+})
@@ -0,0 +1,108 @@
+package api
+
+import (
+  "fmt"
+  "net/http"
+  "strings"
+  "time"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  ErrUnknownAPIVersion = func(version string) error {
+    return fmt.Errorf("api version `%s` is not declared in `api.versions`", version)
+  }
+)
+
+type VersionInfo struct {
+  Name       string     `json:"name"`
+  Prefix     string     `json:"prefix"`
+  Deprecated bool       `json:"deprecated"`
+  Since      *time.Time `json:"since,omitempty"`
+  Sunset     *time.Time `json:"sunset,omitempty"`
+  Link       string     `json:"link,omitempty"`
+}
+
+func (r *Router) RegisterVersion(version string, controller Controller, middleware ...func(ctx *gin.Context)) {
+  apiVersion, ok := r.api.Version(version)
+  if !ok {
+    panic(ErrUnknownAPIVersion(version))
+  }
+
+  r.register(apiVersion.Prefix, apiVersion, controller, middleware...)
+}
+
+func (r *Router) Versions() []VersionInfo {
+  versions := make([]VersionInfo, len(r.api.Versions))
+
+  for i, version := range r.api.Versions {
+    versions[i] = VersionInfo{
+      Name:       version.Name,
+      Prefix:     version.Prefix,
+      Deprecated: version.IsDeprecated(),
+      Since:      optionalTime(version.Deprecation.Since),
+      Sunset:     optionalTime(version.Deprecation.Sunset),
+      Link:       version.Deprecation.Link,
+    }
+  }
+
+  return versions
+}
+
+func (r *Router) defaultVersion() (config.APIVersion, bool) {
+  for _, version := range r.api.Versions {
+    if version.Prefix == r.api.PathPrefix {
+      return version, true
+    }
+  }
+
+  return config.APIVersion{}, false
+}
+
+func deprecationMiddleware(deprecation config.Deprecation) gin.HandlerFunc {
+  return func(c *gin.Context) {
+    if !deprecation.Since.IsZero() {
+      c.Header("Deprecation", fmt.Sprintf("@%d", deprecation.Since.Unix()))
+    } else {
+      c.Header("Deprecation", "true")
+    }
+
+    if !deprecation.Sunset.IsZero() {
+      c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+    }
+
+    if deprecation.Link != "" {
+      c.Header("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, deprecation.Link))
+    }
+
+    c.Next()
+  }
+}
+
+func allVersions(registeredRoute) bool {
+  return true
+}
+
+func forVersion(version string) func(registeredRoute) bool {
+  name := strings.Trim(version, "/")
+
+  return func(route registeredRoute) bool {
+    return route.version.Name == name
+  }
+}
+
+func optionalTime(t time.Time) *time.Time {
+  if t.IsZero() {
+    return nil
+  }
+
+  return &t
+}
+
+func versionsHandler(router *Router) func(c *gin.Context) {
+  return func(c *gin.Context) {
+    c.JSON(http.StatusOK, router.Versions())
+  }
+}
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("API versions", func() {
+	var (
+		router api.Router
+		since  = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+		sunset = time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+	)
+
+	perform := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rr
+	}
+
+	BeforeEach(func() {
+		v1 := config.NewAPIVersion("v1")
+		v1.Deprecation = config.Deprecation{Since: since, Sunset: sunset, Link: "https://example.com/migrate"}
+
+		router = api.NewRouter(config.API{
+			Port:         8123,
+			PathPrefix:   "/v1",
+			ExposeRoutes: true,
+			OpenAPI:      config.OpenAPI{Enabled: true},
+			Versions:     []config.APIVersion{v1, config.NewAPIVersion("v2")},
+		})
+
+		for _, version := range []string{"v1", "v2"} {
+			name := version
+
+			controller := api.NewController("/things")
+			controller.Register(api.NewGETRoute("", func(c *gin.Context) {
+				c.String(http.StatusOK, name)
+			}))
+			router.RegisterVersion(name, controller)
+		}
+	})
+
+	It("should serve each version under its own prefix", func() {
+		Expect(perform("/v1/things").Body.String()).To(Equal("v1"))
+		Expect(perform("/v2/things").Body.String()).To(Equal("v2"))
+	})
+
+	It("should emit deprecation headers for deprecated versions only", func() {
+		deprecated := perform("/v1/things")
+		Expect(deprecated.Header().Get("Deprecation")).To(Equal("@1767225600"))
+		Expect(deprecated.Header().Get("Sunset")).To(Equal("Thu, 31 Dec 2026 00:00:00 GMT"))
+		Expect(deprecated.Header().Get("Link")).To(Equal(`<https://example.com/migrate>; rel="deprecation"`))
+
+		current := perform("/v2/things")
+		Expect(current.Header().Get("Deprecation")).To(BeEmpty())
+		Expect(current.Header().Get("Sunset")).To(BeEmpty())
+	})
+
+	It("should panic when registering an undeclared version", func() {
+		Expect(func() {
+			router.RegisterVersion("v3", api.NewController("/things"))
+		}).To(Panic())
+	})
+
+	It("should filter introspection by version", func() {
+		routes := router.VersionRoutes("v2")
+
+		Expect(routes).To(HaveLen(1))
+		Expect(routes[0].Path).To(Equal("/v2/things"))
+		Expect(routes[0].Version).To(Equal("v2"))
+		Expect(routes[0].Deprecated).To(BeFalse())
+
+		var fromEndpoint []api.RouteInfo
+		Expect(json.Unmarshal(perform("/v1/info/routes?version=v2").Body.Bytes(), &fromEndpoint)).To(Succeed())
+		Expect(fromEndpoint).To(Equal(routes))
+	})
+
+	It("should mark deprecated operations in the per-version OpenAPI document", func() {
+		v1 := router.VersionOpenAPI("v1")
+		Expect(v1.Paths).To(HaveKey("/v1/things"))
+		Expect(v1.Paths).NotTo(HaveKey("/v2/things"))
+		Expect(v1.Paths["/v1/things"]["get"].Deprecated).To(BeTrue())
+
+		v2 := router.VersionOpenAPI("v2")
+		Expect(v2.Paths).To(HaveLen(1))
+		Expect(v2.Paths["/v2/things"]["get"].Deprecated).To(BeFalse())
+	})
+
+	It("should describe the declared versions", func() {
+		var versions []api.VersionInfo
+		Expect(json.Unmarshal(perform("/v1/info/versions").Body.Bytes(), &versions)).To(Succeed())
+
+		Expect(versions).To(HaveLen(2))
+		Expect(versions[0].Name).To(Equal("v1"))
+		Expect(versions[0].Deprecated).To(BeTrue())
+		Expect(versions[0].Sunset.Equal(sunset)).To(BeTrue())
+		Expect(versions[1].Name).To(Equal("v2"))
+		Expect(versions[1].Prefix).To(Equal("/v2"))
+		Expect(versions[1].Sunset).To(BeNil())
+	})
+})
@@ -0,0 +1,502 @@
+package api
+
+import (
+  "bufio"
+  "context"
+  "crypto/sha1"
+  "encoding/base64"
+  "encoding/binary"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net"
+  "net/http"
+  "strings"
+  "sync"
+  "time"
+  "unicode/utf8"
+
+  "github.com/gin-gonic/gin"
+)
+
+type MessageType byte
+
+const (
+  continuationFrame MessageType = 0x0
+  TextMessage       MessageType = 0x1
+  BinaryMessage     MessageType = 0x2
+  closeFrame        MessageType = 0x8
+  pingFrame         MessageType = 0x9
+  pongFrame         MessageType = 0xa
+)
+
+const (
+  CloseNormalClosure           = 1000
+  CloseGoingAway               = 1001
+  CloseProtocolError           = 1002
+  CloseUnsupportedData         = 1003
+  CloseInvalidFramePayloadData = 1007
+  CloseMessageTooBig           = 1009
+  CloseInternalError           = 1011
+  closeNoStatusReceived        = 1005
+  closeAbnormalClosure         = 1006
+  maxCloseReasonLength         = 123
+)
+
+var (
+  ErrWebSocketUpgradeRequired = NewError(http.StatusUpgradeRequired, "upgrade_required", "expected a WebSocket upgrade request")
+  errWebSocketProtocol        = errors.New("websocket protocol violation")
+  errWebSocketMessageTooBig   = errors.New("websocket message too big")
+  webSocketGUID               = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+  webSocketWriteWait          = 10 * time.Second
+  webSocketMessageQueueSize   = 16
+  MaxWebSocketMessageSize     = int64(1 << 20)
+)
+
+type WebSocketMessage struct {
+  Type MessageType
+  Data []byte
+}
+
+type WebSocketConn struct {
+  writeMu   sync.Mutex
+  conn      net.Conn
+  rw        *bufio.ReadWriter
+  request   *http.Request
+  ctx       context.Context
+  cancel    context.CancelFunc
+  messages  chan WebSocketMessage
+  heartbeat time.Duration
+  closed    bool
+  closeOnce sync.Once
+}
+
+func NewWebSocketRoute(uri string, handler func(conn *WebSocketConn)) Route {
+  route := newRoute(http.MethodGet, uri, nil)
+  route.streamHandler = handler
+  route.stream = func(streams *streamRegistry, heartbeat time.Duration) func(c *gin.Context) {
+    return webSocketHandler(streams, heartbeat, handler)
+  }
+
+  return route
+}
+
+func webSocketHandler(streams *streamRegistry, heartbeat time.Duration, handler func(conn *WebSocketConn)) func(c *gin.Context) {
+  return func(c *gin.Context) {
+    if !streams.open() {
+      RenderError(c, NewError(http.StatusServiceUnavailable, "shutting_down", "server is shutting down"))
+      return
+    }
+    defer streams.release()
+
+    if !streams.allowsOrigin(c.Request) {
+      RenderError(c, ErrForbidden("origin is not allowed to open a WebSocket"))
+      return
+    }
+
+    conn, err := upgradeWebSocket(c, heartbeat)
+    if err != nil {
+      RenderError(c, err)
+      return
+    }
+
+    go conn.readLoop()
+    go conn.keepAlive()
+    go func() {
+      select {
+      case <-streams.closing:
+        _ = conn.Close(CloseGoingAway, "server shutting down")
+      case <-conn.Done():
+      }
+    }()
+
+    defer func() {
+      _ = conn.Close(CloseNormalClosure, "")
+    }()
+
+    handler(conn)
+  }
+}
+
+func upgradeWebSocket(c *gin.Context, heartbeat time.Duration) (*WebSocketConn, error) {
+  key := c.GetHeader("Sec-WebSocket-Key")
+
+  if !headerContainsToken(c.Request.Header, "Connection", "upgrade") ||
+    !headerContainsToken(c.Request.Header, "Upgrade", "websocket") ||
+    key == "" {
+    c.Header("Upgrade", "websocket")
+
+    return nil, ErrWebSocketUpgradeRequired
+  }
+
+  if c.GetHeader("Sec-WebSocket-Version") != "13" {
+    c.Header("Sec-WebSocket-Version", "13")
+
+    return nil, ErrBadRequest("unsupported `Sec-WebSocket-Version`")
+  }
+
+  c.Writer.WriteHeader(http.StatusSwitchingProtocols)
+
+  netConn, rw, err := c.Writer.Hijack()
+  if err != nil {
+    return nil, ErrInternal().WithCause(err)
+  }
+
+  _ = netConn.SetDeadline(time.Time{})
+
+  response := "HTTP/1.1 101 Switching Protocols\r\n" +
+    "Upgrade: websocket\r\n" +
+    "Connection: Upgrade\r\n" +
+    "Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n"
+
+  if id := RequestID(c); id != "" {
+    response += RequestIDHeader + ": " + id + "\r\n"
+  }
+
+  if _, err := rw.WriteString(response + "\r\n"); err != nil {
+    _ = netConn.Close()
+
+    return nil, err
+  }
+
+  if err := rw.Flush(); err != nil {
+    _ = netConn.Close()
+
+    return nil, err
+  }
+
+  ctx, cancel := context.WithCancel(c.Request.Context())
+
+  return &WebSocketConn{
+    conn:      netConn,
+    rw:        rw,
+    request:   c.Request,
+    ctx:       ctx,
+    cancel:    cancel,
+    messages:  make(chan WebSocketMessage, webSocketMessageQueueSize),
+    heartbeat: heartbeat,
+  }, nil
+}
+
+func webSocketAccept(key string) string {
+  sum := sha1.Sum([]byte(key + webSocketGUID))
+
+  return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+  for _, value := range header.Values(name) {
+    for _, part := range strings.Split(value, ",") {
+      if strings.EqualFold(strings.TrimSpace(part), token) {
+        return true
+      }
+    }
+  }
+
+  return false
+}
+
+func (w *WebSocketConn) Context() context.Context {
+  return w.ctx
+}
+
+func (w *WebSocketConn) Done() <-chan struct{} {
+  return w.ctx.Done()
+}
+
+func (w *WebSocketConn) Request() *http.Request {
+  return w.request
+}
+
+func (w *WebSocketConn) Messages() <-chan WebSocketMessage {
+  return w.messages
+}
+
+func (w *WebSocketConn) WriteMessage(messageType MessageType, data []byte) error {
+  if messageType != TextMessage && messageType != BinaryMessage {
+    return errWebSocketProtocol
+  }
+
+  return w.writeFrame(messageType, data)
+}
+
+func (w *WebSocketConn) WriteJSON(v interface{}) error {
+  data, err := json.Marshal(v)
+  if err != nil {
+    return err
+  }
+
+  return w.WriteMessage(TextMessage, data)
+}
+
+func (w *WebSocketConn) Close(code int, reason string) error {
+  var err error
+
+  w.closeOnce.Do(func() {
+    reason = truncateCloseReason(reason)
+
+    payload := make([]byte, 2, 2+len(reason))
+    binary.BigEndian.PutUint16(payload, uint16(code))
+    payload = append(payload, reason...)
+
+    w.writeMu.Lock()
+    if !w.closed {
+      err = w.writeFrameLocked(closeFrame, payload)
+      w.closed = true
+    }
+    w.writeMu.Unlock()
+
+    w.cancel()
+
+    if closeErr := w.conn.Close(); err == nil {
+      err = closeErr
+    }
+  })
+
+  return err
+}
+
+func truncateCloseReason(reason string) string {
+  if len(reason) <= maxCloseReasonLength {
+    return reason
+  }
+
+  end := maxCloseReasonLength
+  for end > 0 && !utf8.RuneStart(reason[end]) {
+    end--
+  }
+
+  return reason[:end]
+}
+
+func validCloseCode(code int) bool {
+  switch {
+  case code >= 3000 && code <= 4999:
+    return true
+  case code < CloseNormalClosure || code > CloseInternalError:
+    return false
+  default:
+    return code != 1004 && code != closeNoStatusReceived && code != closeAbnormalClosure
+  }
+}
+
+func (w *WebSocketConn) writeFrame(opcode MessageType, payload []byte) error {
+  w.writeMu.Lock()
+  defer w.writeMu.Unlock()
+
+  if w.closed {
+    return ErrStreamClosed
+  }
+
+  return w.writeFrameLocked(opcode, payload)
+}
+
+func (w *WebSocketConn) writeFrameLocked(opcode MessageType, payload []byte) error {
+  header := make([]byte, 2, 10)
+  header[0] = 0x80 | byte(opcode)
+
+  switch length := len(payload); {
+  case length <= 125:
+    header[1] = byte(length)
+  case length <= 0xffff:
+    header[1] = 126
+    header = append(header, 0, 0)
+    binary.BigEndian.PutUint16(header[2:], uint16(length))
+  default:
+    header[1] = 127
+    header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+    binary.BigEndian.PutUint64(header[2:], uint64(length))
+  }
+
+  _ = w.conn.SetWriteDeadline(time.Now().Add(webSocketWriteWait))
+
+  if _, err := w.rw.Write(header); err != nil {
+    return err
+  }
+
+  if _, err := w.rw.Write(payload); err != nil {
+    return err
+  }
+
+  return w.rw.Flush()
+}
+
+func (w *WebSocketConn) readFrame() (bool, MessageType, []byte, error) {
+  var header [2]byte
+  if _, err := io.ReadFull(w.rw, header[:]); err != nil {
+    return false, 0, nil, err
+  }
+
+  fin := header[0]&0x80 != 0
+  opcode := MessageType(header[0] & 0x0f)
+  masked := header[1]&0x80 != 0
+  length := uint64(header[1] & 0x7f)
+
+  if header[0]&0x70 != 0 || !masked {
+    return false, 0, nil, errWebSocketProtocol
+  }
+
+  switch length {
+  case 126:
+    var extended [2]byte
+    if _, err := io.ReadFull(w.rw, extended[:]); err != nil {
+      return false, 0, nil, err
+    }
+
+    length = uint64(binary.BigEndian.Uint16(extended[:]))
+  case 127:
+    var extended [8]byte
+    if _, err := io.ReadFull(w.rw, extended[:]); err != nil {
+      return false, 0, nil, err
+    }
+
+    length = binary.BigEndian.Uint64(extended[:])
+  }
+
+  if opcode >= closeFrame && (length > 125 || !fin) {
+    return false, 0, nil, errWebSocketProtocol
+  }
+
+  if length > uint64(MaxWebSocketMessageSize) {
+    return false, 0, nil, errWebSocketMessageTooBig
+  }
+
+  var mask [4]byte
+  if _, err := io.ReadFull(w.rw, mask[:]); err != nil {
+    return false, 0, nil, err
+  }
+
+  payload := make([]byte, length)
+  if _, err := io.ReadFull(w.rw, payload); err != nil {
+    return false, 0, nil, err
+  }
+
+  for i := range payload {
+    payload[i] ^= mask[i%4]
+  }
+
+  return fin, opcode, payload, nil
+}
+
+func (w *WebSocketConn) readLoop() {
+  defer w.cancel()
+
+  var (
+    messageType MessageType
+    message     []byte
+    fragmented  bool
+  )
+
+  for {
+    _ = w.conn.SetReadDeadline(time.Now().Add(2 * w.heartbeat))
+
+    fin, opcode, payload, err := w.readFrame()
+    switch {
+    case errors.Is(err, errWebSocketProtocol):
+      _ = w.Close(CloseProtocolError, err.Error())
+      return
+    case errors.Is(err, errWebSocketMessageTooBig):
+      _ = w.Close(CloseMessageTooBig, err.Error())
+      return
+    case err != nil:
+      w.writeMu.Lock()
+      w.closed = true
+      w.writeMu.Unlock()
+
+      _ = w.conn.Close()
+      return
+    }
+
+    switch opcode {
+    case pingFrame:
+      _ = w.writeFrame(pongFrame, payload)
+      continue
+    case pongFrame:
+      continue
+    case closeFrame:
+      _ = w.Close(closeReply(payload), "")
+      return
+    case TextMessage, BinaryMessage:
+      if fragmented {
+        _ = w.Close(CloseProtocolError, "expected continuation frame")
+        return
+      }
+
+      messageType, message, fragmented = opcode, payload, !fin
+    case continuationFrame:
+      if !fragmented {
+        _ = w.Close(CloseProtocolError, "unexpected continuation frame")
+        return
+      }
+
+      if int64(len(message)+len(payload)) > MaxWebSocketMessageSize {
+        _ = w.Close(CloseMessageTooBig, errWebSocketMessageTooBig.Error())
+        return
+      }
+
+      message = append(message, payload...)
+      fragmented = !fin
+    default:
+      _ = w.Close(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+      return
+    }
+
+    if fragmented {
+      continue
+    }
+
+    if messageType == TextMessage && !utf8.Valid(message) {
+      _ = w.Close(CloseInvalidFramePayloadData, "text message is not valid UTF-8")
+      return
+    }
+
+    select {
+    case w.messages <- WebSocketMessage{Type: messageType, Data: message}:
+    case <-w.ctx.Done():
+      return
+    default:
+      _ = w.Close(CloseInternalError, "message queue is full")
+      return
+    }
+
+    message = nil
+  }
+}
+
+func closeReply(payload []byte) int {
+  switch {
+  case len(payload) == 0:
+    return CloseNormalClosure
+  case len(payload) == 1:
+    return CloseProtocolError
+  }
+
+  code := int(binary.BigEndian.Uint16(payload))
+  if !validCloseCode(code) {
+    return CloseProtocolError
+  }
+
+  if !utf8.Valid(payload[2:]) {
+    return CloseInvalidFramePayloadData
+  }
+
+  return code
+}
+
+func (w *WebSocketConn) keepAlive() {
+  ticker := time.NewTicker(w.heartbeat)
+  defer ticker.Stop()
+
+  for {
+    select {
+    case <-w.ctx.Done():
+      return
+    case <-ticker.C:
+      if err := w.writeFrame(pingFrame, nil); err != nil {
+        _ = w.Close(CloseGoingAway, "")
+        return
+      }
+    }
+  }
+}
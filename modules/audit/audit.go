@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wgentry22/agora/modules/logg"
+)
+
+var (
+	mutatingMethods = map[string]bool{
+		http.MethodPost:   true,
+		http.MethodPut:    true,
+		http.MethodPatch:  true,
+		http.MethodDelete: true,
+	}
+)
+
+type Record struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	Subject   string    `json:"subject" gorm:"index"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"requestId" gorm:"index"`
+	Timestamp time.Time `json:"timestamp" gorm:"index"`
+}
+
+func (Record) TableName() string {
+	return "audit_records"
+}
+
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, record Record) error
+}
+
+func Middleware(sinks ...Sink) func(*gin.Context) {
+	return func(c *gin.Context) {
+		if !mutatingMethods[c.Request.Method] {
+			c.Next()
+
+			return
+		}
+
+		timestamp := time.Now().UTC()
+
+		c.Next()
+
+		record := Record{
+			Subject:   c.GetString("subject"),
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+			RequestID: requestID(c),
+			Timestamp: timestamp,
+		}
+
+		for _, sink := range sinks {
+			if err := sink.Write(c.Request.Context(), record); err != nil {
+				logg.Root().
+					WithContext(c.Request.Context()).
+					WithField("sink", sink.Name()).
+					WithError(err).
+					Error("Failed to write audit record")
+			}
+		}
+	}
+}
+
+func requestID(c *gin.Context) string {
+	if id, ok := logg.RequestIDFromContext(c.Request.Context()); ok {
+		return id
+	}
+
+	return c.GetHeader("X-Request-ID")
+}
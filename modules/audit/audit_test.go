@@ -0,0 +1,108 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/audit"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (r *recordingSink) Name() string {
+	return "recording"
+}
+
+func (r *recordingSink) Write(ctx context.Context, record audit.Record) error {
+	r.records = append(r.records, record)
+
+	return nil
+}
+
+var _ = Describe("Audit Middleware", func() {
+	var (
+		ts     *httptest.Server
+		sink   *recordingSink
+		buf    bytes.Buffer
+		router api.Router
+	)
+
+	request := func(method, uri string) *http.Response {
+		req, err := http.NewRequest(method, fmt.Sprintf("%s/api/widgets%s", ts.URL, uri), strings.NewReader(""))
+		Expect(err).To(BeNil())
+		req.Header.Set("Authorization", "Bearer "+auth.MockToken("alice", nil))
+		req.Header.Set("X-Request-ID", "req-1")
+
+		res, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+
+		return res
+	}
+
+	BeforeEach(func() {
+		buf.Reset()
+		sink = &recordingSink{}
+		auth.Use(config.Auth{Vendor: config.AuthVendorMock})
+
+		logger := logg.NewLogrusLogger(config.Logging{Level: "info"}).WithWriter(&buf)
+
+		controller := api.NewController("/widgets")
+		controller.Use(audit.Middleware(sink, audit.NewLoggerSink(logger)))
+		controller.Register(api.NewGETRoute("/:id", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		}))
+		controller.Register(api.NewPUTRoute("/:id", func(c *gin.Context) {
+			c.Status(http.StatusAccepted)
+		}))
+
+		router = api.NewRouter(config.API{PathPrefix: "/api"})
+		router.RegisterWithMiddleware(controller, auth.RequiresTokenMiddleware)
+
+		ts = httptest.NewServer(router.Handler())
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	It("should not record non-mutating requests", func() {
+		res := request(http.MethodGet, "/1")
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(sink.records).To(BeEmpty())
+		Expect(buf.Len()).To(BeZero())
+	})
+
+	It("should record mutating requests to every sink", func() {
+		res := request(http.MethodPut, "/1")
+		Expect(res.StatusCode).To(Equal(http.StatusAccepted))
+
+		Expect(sink.records).To(HaveLen(1))
+
+		record := sink.records[0]
+		Expect(record.Subject).To(Equal("alice"))
+		Expect(record.Method).To(Equal(http.MethodPut))
+		Expect(record.Route).To(Equal("/api/widgets/:id"))
+		Expect(record.Status).To(Equal(http.StatusAccepted))
+		Expect(record.RequestID).To(Equal("req-1"))
+		Expect(record.Timestamp.IsZero()).To(BeFalse())
+
+		var entry map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &entry)).To(Succeed())
+		Expect(entry["subject"]).To(Equal("alice"))
+		Expect(entry["route"]).To(Equal("/api/widgets/:id"))
+	})
+})
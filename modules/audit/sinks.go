@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/broker"
+	"github.com/wgentry22/agora/modules/logg"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFailedToMigrateAuditRecords = errors.New("failed to migrate audit records table")
+)
+
+type loggerSink struct {
+	logger logg.Logger
+}
+
+func NewLoggerSink(logger logg.Logger) Sink {
+	return &loggerSink{logger}
+}
+
+func (l *loggerSink) Name() string {
+	return "logger"
+}
+
+func (l *loggerSink) Write(ctx context.Context, record Record) error {
+	l.logger.
+		WithContext(ctx).
+		WithField("audit", true).
+		WithField("subject", record.Subject).
+		WithField("method", record.Method).
+		WithField("route", record.Route).
+		WithField("status", record.Status).
+		WithField("requestId", record.RequestID).
+		WithField("timestamp", record.Timestamp).
+		Info("Audit record")
+
+	return nil
+}
+
+type ormSink struct {
+	db *gorm.DB
+}
+
+func NewORMSink(db *gorm.DB) Sink {
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		panic(errwrap.Wrap(ErrFailedToMigrateAuditRecords, err))
+	}
+
+	return &ormSink{db}
+}
+
+func (o *ormSink) Name() string {
+	return "orm"
+}
+
+func (o *ormSink) Write(ctx context.Context, record Record) error {
+	return o.db.WithContext(ctx).Create(&record).Error
+}
+
+type brokerSink struct {
+	publisher broker.Publisher
+	newEvent  func(key, payload []byte) broker.Event
+}
+
+func NewBrokerSink(publisher broker.Publisher, topic string) Sink {
+	return &brokerSink{
+		publisher: publisher,
+		newEvent:  broker.EventFactory(topic),
+	}
+}
+
+func (b *brokerSink) Name() string {
+	return "broker"
+}
+
+func (b *brokerSink) Write(ctx context.Context, record Record) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	b.publisher.PublishContext(ctx, b.newEvent([]byte(record.Subject), payload))
+
+	return nil
+}
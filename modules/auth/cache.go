@@ -0,0 +1,142 @@
+package auth
+
+import (
+  "crypto/sha256"
+  "encoding/hex"
+  "sync"
+  "time"
+
+  "github.com/prometheus/client_golang/prometheus"
+  "github.com/wgentry22/agora/modules/heartbeat"
+  "github.com/wgentry22/agora/types/config"
+)
+
+var (
+  cacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+    Name: "auth_token_cache_hits_total",
+    Help: "The total number of tokens served from the validated token cache.",
+  })
+  cacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+    Name: "auth_token_cache_misses_total",
+    Help: "The total number of tokens that had to be verified by the auth vendor.",
+  })
+)
+
+type cachedToken struct {
+  token   *Token
+  expires time.Time
+}
+
+type tokenCache struct {
+  mu         sync.Mutex
+  ttl        time.Duration
+  maxEntries int
+  entries    map[string]cachedToken
+}
+
+func newTokenCache(conf config.AuthCache) *tokenCache {
+  return &tokenCache{
+    ttl:        conf.TTL,
+    maxEntries: conf.MaxEntries,
+    entries:    make(map[string]cachedToken),
+  }
+}
+
+func tokenHash(raw string) string {
+  sum := sha256.Sum256([]byte(raw))
+
+  return hex.EncodeToString(sum[:])
+}
+
+func (t *tokenCache) get(raw string) (*Token, bool) {
+  if t == nil {
+    return nil, false
+  }
+
+  t.mu.Lock()
+  defer t.mu.Unlock()
+
+  key := tokenHash(raw)
+
+  entry, ok := t.entries[key]
+  if ok && time.Now().Before(entry.expires) {
+    cacheHits.Inc()
+
+    return entry.token, true
+  }
+
+  if ok {
+    delete(t.entries, key)
+  }
+
+  cacheMisses.Inc()
+
+  return nil, false
+}
+
+func (t *tokenCache) put(raw string, token *Token) {
+  if t == nil {
+    return
+  }
+
+  t.mu.Lock()
+  defer t.mu.Unlock()
+
+  now := time.Now()
+
+  expires := now.Add(t.ttl)
+  if !token.Expires.IsZero() && token.Expires.Before(expires) {
+    expires = token.Expires
+  }
+
+  if !expires.After(now) {
+    return
+  }
+
+  if len(t.entries) >= t.maxEntries {
+    t.prune(now)
+  }
+
+  t.entries[tokenHash(raw)] = cachedToken{token, expires}
+}
+
+func (t *tokenCache) evict(raw string) {
+  if t == nil {
+    return
+  }
+
+  t.mu.Lock()
+  defer t.mu.Unlock()
+
+  delete(t.entries, tokenHash(raw))
+}
+
+func (t *tokenCache) prune(now time.Time) {
+  for key, entry := range t.entries {
+    if !now.Before(entry.expires) {
+      delete(t.entries, key)
+    }
+  }
+
+  for key := range t.entries {
+    if len(t.entries) < t.maxEntries {
+      break
+    }
+
+    delete(t.entries, key)
+  }
+}
+
+type cachePacer struct{}
+
+func (c *cachePacer) Component() string {
+  return "auth_token_cache"
+}
+
+func (c *cachePacer) RegisterWith(registry *prometheus.Registry) {
+  registry.MustRegister(cacheHits, cacheMisses)
+}
+
+func RegisterPacer() {
+  heartbeat.RegisterPacers(&cachePacer{})
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+  "crypto/x509"
+  "errors"
+  "net/http"
+
+  "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/api"
+)
+
+var (
+  ErrClientCertificateRequired = errors.New("verified client certificate required")
+)
+
+func ClientCertificate(r *http.Request) (*x509.Certificate, bool) {
+  if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+    return nil, false
+  }
+
+  return r.TLS.VerifiedChains[0][0], true
+}
+
+func ClientCertificateMiddleware(c *gin.Context) {
+  if _, authenticated := c.Get("subject"); !authenticated {
+    if cert, ok := ClientCertificate(c.Request); ok {
+      setCertificatePrincipal(c, cert)
+    }
+  }
+
+  c.Next()
+}
+
+func RequiresClientCertificateMiddleware(c *gin.Context) {
+  cert, ok := ClientCertificate(c.Request)
+  if !ok {
+    api.RenderError(c, api.ErrUnauthorized(ErrClientCertificateRequired.Error()).WithCause(ErrClientCertificateRequired))
+
+    return
+  }
+
+  setCertificatePrincipal(c, cert)
+  c.Next()
+}
+
+func setCertificatePrincipal(c *gin.Context, cert *x509.Certificate) {
+  subject := cert.Subject.CommonName
+  if subject == "" {
+    subject = cert.Subject.String()
+  }
+
+  c.Set("subject", subject)
+  c.Set("claims", map[string]interface{}{
+    "dn":     cert.Subject.String(),
+    "issuer": cert.Issuer.String(),
+    "serial": cert.SerialNumber.String(),
+  })
+}
@@ -0,0 +1,88 @@
+package auth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/auth"
+)
+
+var _ = Describe("ClientCertificateMiddleware", func() {
+	var (
+		router *gin.Engine
+		cert   = &x509.Certificate{
+			SerialNumber: big.NewInt(42),
+			Subject:      pkix.Name{CommonName: "service-a", Organization: []string{"agora"}},
+			Issuer:       pkix.Name{CommonName: "agora-ca"},
+		}
+	)
+
+	withCertificate := func(req *http.Request) *http.Request {
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}
+
+		return req
+	}
+
+	BeforeEach(func() {
+		router = gin.New()
+		router.GET("/optional", auth.ClientCertificateMiddleware, testHandler)
+		router.GET("/token", func(c *gin.Context) { c.Set("subject", "token-user") }, auth.ClientCertificateMiddleware, testHandler)
+		router.GET("/required", auth.RequiresClientCertificateMiddleware, func(c *gin.Context) {
+			c.JSON(http.StatusOK, auth.Claims(c))
+		})
+	})
+
+	It("should expose the certificate common name as the subject", func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withCertificate(httptest.NewRequest(http.MethodGet, "/optional", nil)))
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.String()).To(Equal(`{"hello":"service-a"}`))
+	})
+
+	It("should expose the certificate details as claims", func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withCertificate(httptest.NewRequest(http.MethodGet, "/required", nil)))
+
+		var claims map[string]string
+		Expect(json.Unmarshal(rr.Body.Bytes(), &claims)).To(Succeed())
+		Expect(claims["serial"]).To(Equal("42"))
+		Expect(claims["issuer"]).To(Equal("CN=agora-ca"))
+		Expect(claims["dn"]).To(Equal("CN=service-a,O=agora"))
+	})
+
+	It("should not replace a subject set by token authentication", func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, withCertificate(httptest.NewRequest(http.MethodGet, "/token", nil)))
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(rr.Body.String()).To(Equal(`{"hello":"token-user"}`))
+	})
+
+	It("should leave the request anonymous when no certificate was verified", func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/optional", nil))
+
+		Expect(rr.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should respond with a problem when a certificate is required", func() {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/required", nil))
+
+		Expect(rr.Code).To(Equal(http.StatusUnauthorized))
+		Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+	})
+})
@@ -1,20 +1,27 @@
 package auth
 
 import (
+  "context"
   "errors"
   "net/http"
   "strings"
   "sync"
 
   "github.com/gin-gonic/gin"
+  "github.com/wgentry22/agora/modules/api"
   "github.com/wgentry22/agora/types/config"
 )
 
 var (
   ErrAuthorizationHeaderRequired = errors.New("authorization header missing")
   ErrAuthConfigurationRequired = errors.New("`RequiresTokenMiddleware` requires that you provide a config.Auth to auth.Use")
+  ErrTokenRevoked = errors.New("token has been revoked")
+  ErrInvalidToken = errors.New("invalid or expired token")
   m sync.Mutex
-  validator TokenValidator
+  verifier TokenVerifier
+  denylist Denylist
+  checkRevoked bool
+  cache *tokenCache
 )
 
 func Use(conf config.Auth) {
@@ -22,37 +29,151 @@ func Use(conf config.Auth) {
   defer m.Unlock()
 
   if conf.Vendor.String() == "firebase" {
-    validator = newFirebaseTokenValidator()
+    verifier = newFirebaseTokenValidator(conf.CheckRevoked, firebaseClientOptions(conf.Args)...)
   } else if conf.Vendor.String() == "mock" {
-    validator = newMockTokenValidator()
+    verifier = newMockTokenValidator()
   }
+
+  checkRevoked = conf.CheckRevoked
+
+  if conf.Cache.Enabled {
+    cache = newTokenCache(conf.Cache)
+  } else {
+    cache = nil
+  }
+}
+
+func UseVerifier(v TokenVerifier) {
+  m.Lock()
+  defer m.Unlock()
+
+  verifier = v
 }
 
 func RequiresTokenMiddleware(c *gin.Context) {
-  if validator == nil {
+  if verifier == nil {
     panic(ErrAuthConfigurationRequired)
   }
 
-  sub, err := validator.Validate(c.Request)
+  token, err := verifyRequest(c.Request)
   if err != nil {
-    c.AbortWithStatus(http.StatusUnauthorized)
+    api.RenderError(c, api.ErrUnauthorized(unauthorizedDetail(err)).WithCause(err))
   } else {
-    c.Set("subject", sub)
+    c.Set("subject", token.Subject)
+    c.Set("claims", token.Claims)
     c.Next()
   }
 }
 
-type mockTokenValidator struct {}
+func unauthorizedDetail(err error) string {
+  if errors.Is(err, ErrAuthorizationHeaderRequired) || errors.Is(err, ErrTokenRevoked) {
+    return err.Error()
+  }
 
-func (m *mockTokenValidator) Validate(r *http.Request) (string, error) {
-  header := r.Header.Get("Authorization")
-  if header != "" && strings.HasPrefix(header, "Bearer "){
-    return "mock", nil
+  return ErrInvalidToken.Error()
+}
+
+type configuredValidator struct{}
+
+func (configuredValidator) Validate(r *http.Request) (string, error) {
+  if verifier == nil {
+    return "", ErrAuthConfigurationRequired
   }
 
-  return "", ErrAuthorizationHeaderRequired
+  token, err := verifyRequest(r)
+  if err != nil {
+    return "", err
+  }
+
+  return token.Subject, nil
 }
 
-func newMockTokenValidator() TokenValidator {
-  return &mockTokenValidator{}
+func (configuredValidator) Verify(ctx context.Context, raw string) (*Token, error) {
+  if verifier == nil {
+    return nil, ErrAuthConfigurationRequired
+  }
+
+  return verifyToken(ctx, raw)
+}
+
+func Validator() TokenValidator {
+  return configuredValidator{}
+}
+
+func Verifier() TokenVerifier {
+  return configuredValidator{}
+}
+
+func verifyRequest(r *http.Request) (*Token, error) {
+  raw, err := bearerToken(r)
+  if err != nil {
+    return nil, err
+  }
+
+  return verifyToken(r.Context(), raw)
+}
+
+func verifyToken(ctx context.Context, raw string) (*Token, error) {
+  tokens := cache
+  if checkRevoked {
+    tokens = nil
+  }
+
+  token, found := tokens.get(raw)
+  if !found {
+    var err error
+    if token, err = verifier.Verify(ctx, raw); err != nil {
+      return nil, err
+    }
+
+    tokens.put(raw, token)
+  }
+
+  if checkRevoked && denylist != nil {
+    revoked, err := denylist.IsRevoked(ctx, raw, token)
+    if err != nil {
+      return nil, err
+    }
+
+    if revoked {
+      cache.evict(raw)
+
+      return nil, ErrTokenRevoked
+    }
+  }
+
+  return token, nil
+}
+
+func bearerToken(r *http.Request) (string, error) {
+  return ParseBearerToken(r.Header.Get("Authorization"))
+}
+
+func ParseBearerToken(header string) (string, error) {
+  if header == "" || !strings.HasPrefix(header, "Bearer ") {
+    return "", ErrAuthorizationHeaderRequired
+  }
+
+  return strings.TrimPrefix(header, "Bearer "), nil
+}
+
+func Subject(c *gin.Context) (string, bool) {
+  subject, ok := c.Get("subject")
+  if !ok {
+    return "", false
+  }
+
+  sub, ok := subject.(string)
+
+  return sub, ok
+}
+
+func Claims(c *gin.Context) map[string]interface{} {
+  if claims, ok := c.Get("claims"); ok {
+    if asMap, isMap := claims.(map[string]interface{}); isMap {
+      return asMap
+    }
+  }
+
+  return map[string]interface{}{}
 }
@@ -1,9 +1,11 @@
 package auth_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo"
@@ -21,6 +23,25 @@ func testHandler(c *gin.Context) {
 	}
 }
 
+type revokingVerifier struct {
+	revoked map[string]bool
+	calls   int
+}
+
+func (v *revokingVerifier) Validate(r *http.Request) (string, error) {
+	return "", nil
+}
+
+func (v *revokingVerifier) Verify(ctx context.Context, raw string) (*auth.Token, error) {
+	v.calls++
+
+	if v.revoked[raw] {
+		return nil, auth.ErrTokenRevoked
+	}
+
+	return &auth.Token{Subject: raw, Expires: time.Now().Add(time.Hour)}, nil
+}
+
 var _ = Describe("AuthMiddleware", func() {
 
 	var (
@@ -78,7 +99,7 @@ var _ = Describe("AuthMiddleware", func() {
 		It("should reach the handler when Bearer Token is present", func() {
 			w := httptest.NewRecorder()
 
-			request.Header.Set("Authorization", "Bearer test")
+			request.Header.Set("Authorization", "Bearer "+auth.MockToken("mock", nil))
 
 			router.ServeHTTP(w, request)
 
@@ -90,5 +111,182 @@ var _ = Describe("AuthMiddleware", func() {
 
 			Expect(body["hello"]).To(Equal("mock"))
 		})
+
+		It("should verify raw tokens outside of HTTP requests", func() {
+			token, err := auth.Verifier().Verify(context.Background(), auth.MockToken("rpc-client", nil))
+			Expect(err).To(BeNil())
+			Expect(token.Subject).To(Equal("rpc-client"))
+
+			_, err = auth.Verifier().Verify(context.Background(), "not-a-token")
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("should distinguish between subjects and expose their claims", func() {
+			router.GET("/claims", auth.RequiresTokenMiddleware, func(c *gin.Context) {
+				sub, _ := auth.Subject(c)
+				c.JSON(http.StatusOK, map[string]interface{}{"sub": sub, "role": auth.Claims(c)["role"]})
+			})
+
+			for subject, role := range map[string]string{"alice": "admin", "bob": "viewer"} {
+				req, err := http.NewRequest(http.MethodGet, "/claims", nil)
+				Expect(err).To(BeNil())
+				req.Header.Set("Authorization", "Bearer "+auth.MockToken(subject, map[string]interface{}{"role": role}))
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				Expect(w.Code).To(Equal(http.StatusOK))
+
+				var body map[string]string
+				err = json.Unmarshal(w.Body.Bytes(), &body)
+				Expect(err).To(BeNil())
+
+				Expect(body["sub"]).To(Equal(subject))
+				Expect(body["role"]).To(Equal(role))
+			}
+		})
+
+		It("should return 401 UNAUTHORIZED when Bearer Token is malformed", func() {
+			w := httptest.NewRecorder()
+
+			request.Header.Set("Authorization", "Bearer test")
+
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should not expose verifier errors in the problem detail", func() {
+			w := httptest.NewRecorder()
+
+			request.Header.Set("Authorization", "Bearer test")
+
+			router.ServeHTTP(w, request)
+
+			var problem map[string]interface{}
+			Expect(json.Unmarshal(w.Body.Bytes(), &problem)).To(Succeed())
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			Expect(problem["detail"]).To(Equal(auth.ErrInvalidToken.Error()))
+		})
+
+		It("should return 401 UNAUTHORIZED when Bearer Token has expired", func() {
+			w := httptest.NewRecorder()
+
+			expired := auth.MockToken("mock", map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})
+			request.Header.Set("Authorization", "Bearer "+expired)
+
+			router.ServeHTTP(w, request)
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("when revocation checks are enabled", func() {
+		var (
+			conf = config.Auth{
+				Vendor:       config.AuthVendorMock,
+				CheckRevoked: true,
+				Cache: config.AuthCache{
+					Enabled:    true,
+					TTL:        time.Minute,
+					MaxEntries: 10,
+				},
+			}
+			denylist *auth.MemoryDenylist
+		)
+
+		JustBeforeEach(func() {
+			denylist = auth.NewMemoryDenylist()
+
+			auth.Use(conf)
+			auth.UseDenylist(denylist)
+			router.GET("/test", auth.RequiresTokenMiddleware, testHandler)
+		})
+
+		AfterEach(func() {
+			auth.UseDenylist(nil)
+		})
+
+		It("should reject tokens once they have been revoked, even when cached", func() {
+			token := auth.MockToken("revoked", nil)
+
+			request := func() int {
+				req, err := http.NewRequest(http.MethodGet, "/test", nil)
+				Expect(err).To(BeNil())
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				return w.Code
+			}
+
+			Expect(request()).To(Equal(http.StatusOK))
+			Expect(request()).To(Equal(http.StatusOK))
+
+			denylist.RevokeToken(token)
+
+			Expect(request()).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("when revocation is checked by the vendor", func() {
+		var (
+			conf = config.Auth{
+				Vendor:       config.AuthVendorMock,
+				CheckRevoked: true,
+				Cache: config.AuthCache{
+					Enabled:    true,
+					TTL:        time.Minute,
+					MaxEntries: 10,
+				},
+			}
+			verifier *revokingVerifier
+		)
+
+		JustBeforeEach(func() {
+			verifier = &revokingVerifier{revoked: make(map[string]bool)}
+
+			auth.Use(conf)
+			auth.UseVerifier(verifier)
+			router.GET("/test", auth.RequiresTokenMiddleware, testHandler)
+		})
+
+		AfterEach(func() {
+			auth.UseDenylist(nil)
+		})
+
+		request := func() int {
+			req, err := http.NewRequest(http.MethodGet, "/test", nil)
+			Expect(err).To(BeNil())
+			req.Header.Set("Authorization", "Bearer vendor-token")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			return w.Code
+		}
+
+		It("should not serve revoked tokens from the cache", func() {
+			Expect(request()).To(Equal(http.StatusOK))
+			Expect(request()).To(Equal(http.StatusOK))
+
+			verifier.revoked["vendor-token"] = true
+
+			Expect(request()).To(Equal(http.StatusUnauthorized))
+			Expect(verifier.calls).To(Equal(3))
+		})
+
+		It("should not serve revoked tokens from the cache when a denylist is also configured", func() {
+			auth.UseDenylist(auth.NewMemoryDenylist())
+
+			Expect(request()).To(Equal(http.StatusOK))
+			Expect(request()).To(Equal(http.StatusOK))
+
+			verifier.revoked["vendor-token"] = true
+
+			Expect(request()).To(Equal(http.StatusUnauthorized))
+			Expect(verifier.calls).To(Equal(3))
+		})
 	})
 })
@@ -0,0 +1,113 @@
+package auth
+
+import (
+  "context"
+  "encoding/base64"
+  "encoding/json"
+  "errors"
+  "net/http"
+  "strings"
+  "time"
+
+  "github.com/hashicorp/errwrap"
+)
+
+var (
+  ErrMalformedMockToken = errors.New("mock token is malformed")
+  ErrMockTokenExpired   = errors.New("mock token has expired")
+  ErrMockTokenSubject   = errors.New("mock token is missing `sub` claim")
+  mockTokenHeader       = map[string]string{"alg": "none", "typ": "JWT"}
+  mockTokenParts        = 3
+)
+
+func MockToken(subject string, claims map[string]interface{}) string {
+  payload := make(map[string]interface{})
+
+  for k, v := range claims {
+    payload[k] = v
+  }
+
+  payload["sub"] = subject
+
+  if _, ok := payload["exp"]; !ok {
+    payload["exp"] = time.Now().Add(time.Hour).Unix()
+  }
+
+  header, err := json.Marshal(mockTokenHeader)
+  if err != nil {
+    panic(err)
+  }
+
+  body, err := json.Marshal(payload)
+  if err != nil {
+    panic(err)
+  }
+
+  encode := base64.RawURLEncoding.EncodeToString
+
+  return encode(header) + "." + encode(body) + "."
+}
+
+type mockTokenValidator struct{}
+
+func (m *mockTokenValidator) Validate(r *http.Request) (string, error) {
+  return validateWith(m, r)
+}
+
+func (m *mockTokenValidator) Verify(ctx context.Context, raw string) (*Token, error) {
+  parts := strings.Split(raw, ".")
+  if len(parts) != mockTokenParts || parts[2] != "" {
+    return nil, ErrMalformedMockToken
+  }
+
+  var header map[string]string
+  if err := decodeMockTokenPart(parts[0], &header); err != nil {
+    return nil, err
+  }
+
+  if header["alg"] != mockTokenHeader["alg"] {
+    return nil, ErrMalformedMockToken
+  }
+
+  var claims map[string]interface{}
+  if err := decodeMockTokenPart(parts[1], &claims); err != nil {
+    return nil, err
+  }
+
+  subject, ok := claims["sub"].(string)
+  if !ok || subject == "" {
+    return nil, ErrMockTokenSubject
+  }
+
+  token := &Token{
+    Subject: subject,
+    Claims:  claims,
+  }
+
+  if exp, ok := claims["exp"].(float64); ok {
+    token.Expires = time.Unix(int64(exp), 0)
+
+    if !time.Now().Before(token.Expires) {
+      return nil, ErrMockTokenExpired
+    }
+  }
+
+  return token, nil
+}
+
+func decodeMockTokenPart(part string, into interface{}) error {
+  data, err := base64.RawURLEncoding.DecodeString(part)
+  if err != nil {
+    return errwrap.Wrap(ErrMalformedMockToken, err)
+  }
+
+  if err := json.Unmarshal(data, into); err != nil {
+    return errwrap.Wrap(ErrMalformedMockToken, err)
+  }
+
+  return nil
+}
+
+func newMockTokenValidator() TokenVerifier {
+  return &mockTokenValidator{}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+  "context"
+  "sync"
+)
+
+type Denylist interface {
+  IsRevoked(ctx context.Context, raw string, token *Token) (bool, error)
+}
+
+func UseDenylist(d Denylist) {
+  m.Lock()
+  defer m.Unlock()
+
+  denylist = d
+}
+
+type MemoryDenylist struct {
+  mu       sync.RWMutex
+  tokens   map[string]struct{}
+  subjects map[string]struct{}
+}
+
+func NewMemoryDenylist() *MemoryDenylist {
+  return &MemoryDenylist{
+    tokens:   make(map[string]struct{}),
+    subjects: make(map[string]struct{}),
+  }
+}
+
+func (d *MemoryDenylist) RevokeToken(raw string) {
+  d.mu.Lock()
+  defer d.mu.Unlock()
+
+  d.tokens[tokenHash(raw)] = struct{}{}
+}
+
+func (d *MemoryDenylist) RevokeSubject(subject string) {
+  d.mu.Lock()
+  defer d.mu.Unlock()
+
+  d.subjects[subject] = struct{}{}
+}
+
+func (d *MemoryDenylist) IsRevoked(ctx context.Context, raw string, token *Token) (bool, error) {
+  d.mu.RLock()
+  defer d.mu.RUnlock()
+
+  if _, ok := d.tokens[tokenHash(raw)]; ok {
+    return true, nil
+  }
+
+  _, ok := d.subjects[token.Subject]
+
+  return ok, nil
+}
@@ -3,10 +3,9 @@ package auth
 import (
   "context"
   "errors"
-  "fmt"
   "net/http"
   "os"
-  "strings"
+  "time"
 
   firebase "firebase.google.com/go"
   "firebase.google.com/go/auth"
@@ -23,14 +22,40 @@ type TokenValidator interface {
   Validate(r *http.Request) (string, error)
 }
 
+type TokenVerifier interface {
+  TokenValidator
+  Verify(ctx context.Context, raw string) (*Token, error)
+}
+
+type Token struct {
+  Subject string
+  Expires time.Time
+  Claims  map[string]interface{}
+}
+
+func validateWith(v TokenVerifier, r *http.Request) (string, error) {
+  raw, err := bearerToken(r)
+  if err != nil {
+    return "", err
+  }
+
+  token, err := v.Verify(r.Context(), raw)
+  if err != nil {
+    return "", err
+  }
+
+  return token.Subject, nil
+}
+
 type FirebaseTokenValidator struct {
-  client *auth.Client
+  client       *auth.Client
+  checkRevoked bool
 }
 
-func newFirebaseTokenValidator(opts ...option.ClientOption) TokenValidator {
+func newFirebaseTokenValidator(checkRevoked bool, opts ...option.ClientOption) TokenVerifier {
   app, err := firebase.NewApp(context.Background(), nil, opts...)
   if err != nil {
-    return firebaseTokenValidatorFromEnv()
+    return firebaseTokenValidatorFromEnv(checkRevoked)
   }
 
   client, err := app.Auth(context.Background())
@@ -38,10 +63,20 @@ func newFirebaseTokenValidator(opts ...option.ClientOption) TokenValidator {
     panic(errwrap.Wrap(ErrFailedToConfigureFirebase, err))
   }
 
-  return &FirebaseTokenValidator{client}
+  return &FirebaseTokenValidator{client, checkRevoked}
 }
 
-func firebaseTokenValidatorFromEnv() TokenValidator {
+func firebaseClientOptions(args map[string]interface{}) []option.ClientOption {
+  opts := make([]option.ClientOption, 0)
+
+  if credentialsFile, ok := args["credentialsFile"].(string); ok && credentialsFile != "" {
+    opts = append(opts, option.WithCredentialsFile(credentialsFile))
+  }
+
+  return opts
+}
+
+func firebaseTokenValidatorFromEnv(checkRevoked bool) TokenVerifier {
   _, ok := os.LookupEnv("GOOGLE_APPLICATION_CREDENTIALS")
   if !ok {
     panic(ErrFailedToCreateFirebaseTokenValidator)
@@ -58,23 +93,32 @@ func firebaseTokenValidatorFromEnv() TokenValidator {
     panic(errwrap.Wrap(ErrFailedToConfigureFirebase, err))
   }
 
-  return &FirebaseTokenValidator{client}
+  return &FirebaseTokenValidator{client, checkRevoked}
 }
 
 func (f *FirebaseTokenValidator) Validate(r *http.Request) (string, error) {
-  header := r.Header.Get("Authorization")
-  if header == "" || !strings.HasPrefix(header, "Bearer ") {
-    return "", ErrAuthorizationHeaderRequired
-  }
+  return validateWith(f, r)
+}
 
-  jwt := strings.ReplaceAll(header, "Bearer ", "")
+func (f *FirebaseTokenValidator) Verify(ctx context.Context, raw string) (*Token, error) {
+  var (
+    token *auth.Token
+    err   error
+  )
 
-  token, err := f.client.VerifyIDToken(r.Context(), jwt)
-  if err != nil {
-    return "", err
+  if f.checkRevoked {
+    token, err = f.client.VerifyIDTokenAndCheckRevoked(ctx, raw)
+  } else {
+    token, err = f.client.VerifyIDToken(ctx, raw)
   }
 
-  fmt.Printf("Verified FirebaseToken: %+v\n", *token)
+  if err != nil {
+    return nil, err
+  }
 
-  return token.Subject, nil
+  return &Token{
+    Subject: token.Subject,
+    Expires: time.Unix(token.Expires, 0),
+    Claims:  token.Claims,
+  }, nil
 }
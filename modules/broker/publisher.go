@@ -1,8 +1,11 @@
 package broker
 
 import (
+	"context"
 	"errors"
 
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/logg"
 	"github.com/wgentry22/agora/types/config"
 	"gopkg.in/confluentinc/confluent-kafka-go.v1/kafka"
 )
@@ -10,10 +13,12 @@ import (
 var (
 	ErrProducerConfigurationExpected = errors.New("expected configuration with broker role `producer`")
 	ErrFailedToDeliverMessage        = errors.New("delivery of message failed")
+	RequestIDHeader                  = "X-Request-ID"
 )
 
 type Publisher interface {
 	Publish(event Event)
+	PublishContext(ctx context.Context, event Event)
 	Errors() <-chan error
 }
 
@@ -35,11 +40,15 @@ func newKafkaPublisher(conf config.Broker) Publisher {
 		panic(err)
 	}
 
-	return &kafkaPublisher{
+	publisher := &kafkaPublisher{
 		publisher: pub,
-		events:    make(chan kafka.Event),
+		events:    make(chan kafka.Event, conf.BufferSize),
 		errors:    make(chan error, 1),
 	}
+
+	go publisher.deliveryReports()
+
+	return publisher
 }
 
 type kafkaPublisher struct {
@@ -49,11 +58,50 @@ type kafkaPublisher struct {
 }
 
 func (k *kafkaPublisher) Publish(event Event) {
+	k.PublishContext(context.Background(), event)
+}
+
+func (k *kafkaPublisher) PublishContext(ctx context.Context, event Event) {
 	if event == nil {
 		panic(errors.New("cannot publish nil event"))
 	}
 
-	panic(event)
+	message := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{
+			Topic:     event.Topic(),
+			Partition: kafka.PartitionAny,
+		},
+		Key:     event.Key(),
+		Value:   event.Payload(),
+		Headers: messageHeaders(ctx),
+	}
+
+	if err := k.publisher.Produce(message, k.events); err != nil {
+		k.reportError(errwrap.Wrap(ErrFailedToDeliverMessage, err))
+	}
+}
+
+func messageHeaders(ctx context.Context) []kafka.Header {
+	if id, ok := logg.RequestIDFromContext(ctx); ok {
+		return []kafka.Header{{Key: RequestIDHeader, Value: []byte(id)}}
+	}
+
+	return nil
+}
+
+func (k *kafkaPublisher) deliveryReports() {
+	for event := range k.events {
+		if message, ok := event.(*kafka.Message); ok && message.TopicPartition.Error != nil {
+			k.reportError(errwrap.Wrap(ErrFailedToDeliverMessage, message.TopicPartition.Error))
+		}
+	}
+}
+
+func (k *kafkaPublisher) reportError(err error) {
+	select {
+	case k.errors <- err:
+	default:
+	}
 }
 
 func (k *kafkaPublisher) Errors() <-chan error {
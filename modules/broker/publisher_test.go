@@ -1,6 +1,9 @@
 package broker_test
 
 import (
+	"time"
+
+	"github.com/hashicorp/errwrap"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/wgentry22/agora/modules/broker"
@@ -30,5 +33,18 @@ var _ = Describe("Publisher", func() {
 		It("should be generated by broker.NewPublisher", func() {
 			Expect(publisher).ToNot(BeNil())
 		})
+
+		It("should report failed deliveries on the errors channel", func() {
+			unreachable := conf
+			unreachable.Servers = []string{"127.0.0.1:1"}
+			unreachable.Args = map[string]interface{}{"message.timeout.ms": 100}
+
+			publisher = broker.NewPublisher(unreachable)
+			publisher.Publish(broker.EventFactory("audit")([]byte("key"), []byte("payload")))
+
+			var err error
+			Eventually(publisher.Errors(), 5*time.Second).Should(Receive(&err))
+			Expect(errwrap.Contains(err, broker.ErrFailedToDeliverMessage.Error())).To(BeTrue())
+		})
 	})
 })
@@ -0,0 +1,34 @@
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wgentry22/agora/modules/api"
+)
+
+type proxyPulser struct {
+	proxy *api.Proxy
+}
+
+func NewProxyPulser(proxy *api.Proxy) Pulser {
+	return &proxyPulser{proxy}
+}
+
+func (p *proxyPulser) Component() string {
+	return fmt.Sprintf("proxy:%s", p.proxy.Name())
+}
+
+func (p *proxyPulser) Pulse(ctx context.Context, pulsec chan<- Pulse) {
+	pulse := NewPulse(p.Component())
+
+	if err := p.proxy.Check(ctx); err != nil {
+		pulse.Status = StatusCritical
+	} else if p.proxy.CircuitState() == api.CircuitHalfOpen {
+		pulse.Status = StatusWarn
+	} else {
+		pulse.Status = StatusOK
+	}
+
+	pulsec <- pulse
+}
@@ -0,0 +1,52 @@
+package heartbeat_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/heartbeat"
+)
+
+var _ = Describe("ProxyPulser", func() {
+	var (
+		upstream *httptest.Server
+		healthy  bool
+	)
+
+	pulse := func(pulser heartbeat.Pulser) heartbeat.Pulse {
+		pulsec := make(chan heartbeat.Pulse, 1)
+		pulser.Pulse(context.Background(), pulsec)
+
+		return <-pulsec
+	}
+
+	BeforeEach(func() {
+		healthy = true
+
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if healthy {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		upstream.Close()
+	})
+
+	It("should report upstream health", func() {
+		pulser := heartbeat.NewProxyPulser(api.NewProxy(upstream.URL, api.ProxyOptions{Name: "legacy", HealthPath: "/health"}))
+
+		Expect(pulser.Component()).To(Equal("proxy:legacy"))
+		Expect(pulse(pulser).Status).To(Equal(heartbeat.StatusOK))
+
+		healthy = false
+		Expect(pulse(pulser).Status).To(Equal(heartbeat.StatusCritical))
+	})
+})
@@ -141,37 +141,48 @@ func RegisterPulser(pulsers ...Pulser) {
 	}
 }
 
-func HealthHandler(conf config.Heartbeat) func(*gin.Context) {
-	return func(c *gin.Context) {
-		withTimeout, cancel := context.WithTimeout(context.Background(), conf.Timeout.Read)
-		defer cancel()
+func CollectPulses(ctx context.Context) []Pulse {
+	m.Lock()
+	pulsers := make([]Pulser, 0, len(registeredPulsers))
+	for _, pulser := range registeredPulsers {
+		pulsers = append(pulsers, pulser)
+	}
+	m.Unlock()
 
-		pulsec := make(chan Pulse, len(registeredPulsers))
+	pulsec := make(chan Pulse, len(pulsers))
 
-		for _, pulser := range registeredPulsers {
-			go pulser.Pulse(withTimeout, pulsec)
-		}
+	for _, pulser := range pulsers {
+		go pulser.Pulse(ctx, pulsec)
+	}
 
-		dependencies := make([]Pulse, 0)
+	dependencies := make([]Pulse, 0)
 
-		finished := len(registeredPulsers) == 0
+	finished := len(pulsers) == 0
 
-		for !finished {
-			select {
-			case pulse, ok := <-pulsec:
-				if !ok {
-					finished = true
-				} else {
-					dependencies = append(dependencies, pulse)
+	for !finished {
+		select {
+		case pulse, ok := <-pulsec:
+			if !ok {
+				finished = true
+			} else {
+				dependencies = append(dependencies, pulse)
 
-					if len(dependencies) == len(registeredPulsers) {
-						finished = true
-					}
+				if len(dependencies) == len(pulsers) {
+					finished = true
 				}
 			}
 		}
+	}
+
+	return dependencies
+}
+
+func HealthHandler(conf config.Heartbeat) func(*gin.Context) {
+	return func(c *gin.Context) {
+		withTimeout, cancel := context.WithTimeout(context.Background(), conf.Timeout.Read)
+		defer cancel()
 
-		response := NewHealthCheckResponse(conf.Info(), dependencies)
+		response := NewHealthCheckResponse(conf.Info(), CollectPulses(withTimeout))
 
 		c.JSON(response.HTTPStatus(), response)
 	}
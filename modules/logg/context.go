@@ -0,0 +1,43 @@
+package logg
+
+import (
+	"context"
+)
+
+type contextFieldsKey struct{}
+
+func ContextWithField(ctx context.Context, key string, value interface{}) context.Context {
+	fields := make(map[string]interface{})
+
+	for k, v := range FieldsFromContext(ctx) {
+		fields[k] = v
+	}
+
+	fields[key] = value
+
+	return context.WithValue(ctx, contextFieldsKey{}, fields)
+}
+
+func FieldsFromContext(ctx context.Context) map[string]interface{} {
+	if ctx == nil {
+		return nil
+	}
+
+	if fields, ok := ctx.Value(contextFieldsKey{}).(map[string]interface{}); ok {
+		return fields
+	}
+
+	return nil
+}
+
+var RequestIDField = "request_id"
+
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return ContextWithField(ctx, RequestIDField, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := FieldsFromContext(ctx)[RequestIDField].(string)
+
+	return id, ok && id != ""
+}
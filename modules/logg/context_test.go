@@ -0,0 +1,62 @@
+package logg_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Request ID", func() {
+	var (
+		buf    bytes.Buffer
+		ctx    context.Context
+		logger logg.Logger
+	)
+
+	BeforeEach(func() {
+		buf.Reset()
+		ctx = logg.ContextWithRequestID(context.Background(), "req-42")
+		logger = logg.NewLogrusLogger(config.Logging{
+			Level:       "info",
+			OutputPaths: []string{"stdout"},
+		}).WithWriter(&buf)
+	})
+
+	decode := func() map[string]interface{} {
+		var entry map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &entry)).To(Succeed())
+
+		return entry
+	}
+
+	It("should be retrievable from the context", func() {
+		id, ok := logg.RequestIDFromContext(ctx)
+		Expect(ok).To(BeTrue())
+		Expect(id).To(Equal("req-42"))
+
+		_, ok = logg.RequestIDFromContext(context.Background())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should be included when logging with the context", func() {
+		logger.WithContext(ctx).Info("hello")
+
+		Expect(decode()[logg.RequestIDField]).To(Equal("req-42"))
+	})
+
+	It("should be included in gorm trace lines", func() {
+		logg.ForGorm(logger).Trace(ctx, time.Now(), func() (string, int64) {
+			return "SELECT 1", 1
+		}, nil)
+
+		entry := decode()
+		Expect(entry[logg.RequestIDField]).To(Equal("req-42"))
+		Expect(entry["msg"]).To(ContainSubstring("SELECT 1"))
+	})
+})
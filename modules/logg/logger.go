@@ -154,7 +154,7 @@ func (l logrusAdapter) WithWriter(writer io.Writer) Logger {
 func (l logrusAdapter) WithContext(ctx context.Context) Logger {
 	return &logrusAdapter{
 		conf:   l.conf,
-		logger: l.logger.WithContext(ctx),
+		logger: l.logger.WithContext(ctx).WithFields(FieldsFromContext(ctx)),
 	}
 }
 
@@ -0,0 +1,116 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/api"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFailedToMigrateIdempotencyKeys = errors.New("failed to migrate idempotency keys")
+)
+
+type idempotencyKey struct {
+	Key         string `gorm:"primaryKey"`
+	Fingerprint string
+	Completed   bool
+	Status      int
+	Header      []byte
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+func (idempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+type IdempotencyStore struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyStore(db *gorm.DB) *IdempotencyStore {
+	if err := db.AutoMigrate(&idempotencyKey{}); err != nil {
+		panic(errwrap.Wrap(ErrFailedToMigrateIdempotencyKeys, err))
+	}
+
+	return &IdempotencyStore{db}
+}
+
+func (s *IdempotencyStore) Begin(ctx context.Context, key, fingerprint string, lockTimeout time.Duration) (response *api.IdempotentResponse, err error) {
+	now := time.Now()
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("key = ? AND expires_at <= ?", key, now).Delete(&idempotencyKey{}).Error; err != nil {
+			return err
+		}
+
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&idempotencyKey{
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(lockTimeout),
+		})
+		if created.Error != nil {
+			return created.Error
+		}
+
+		if created.RowsAffected == 1 {
+			return nil
+		}
+
+		var stored idempotencyKey
+		if err := tx.First(&stored, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		if stored.Fingerprint != fingerprint {
+			return api.ErrIdempotencyKeyReused
+		}
+
+		if !stored.Completed {
+			return api.ErrIdempotencyKeyInFlight
+		}
+
+		header := make(http.Header)
+		if len(stored.Header) > 0 {
+			if err := json.Unmarshal(stored.Header, &header); err != nil {
+				return err
+			}
+		}
+
+		response = &api.IdempotentResponse{Status: stored.Status, Header: header, Body: stored.Body}
+
+		return nil
+	})
+
+	return response, err
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, response api.IdempotentResponse, retention time.Duration) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&idempotencyKey{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"completed":  true,
+		"status":     response.Status,
+		"header":     header,
+		"body":       response.Body,
+		"expires_at": time.Now().Add(retention),
+	}).Error
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ? AND completed = ?", key, false).Delete(&idempotencyKey{}).Error
+}
+
+func (s *IdempotencyStore) Prune(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&idempotencyKey{}).Error
+}
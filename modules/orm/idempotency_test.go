@@ -0,0 +1,61 @@
+package orm_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/orm"
+)
+
+var _ = Describe("IdempotencyStore", func() {
+	var (
+		store *orm.IdempotencyStore
+		ctx   = context.Background()
+	)
+
+	JustBeforeEach(func() {
+		orm.UseConfig(mustParseConfig())
+		store = orm.NewIdempotencyStore(orm.Get())
+	})
+
+	It("should lock, complete and replay a key", func() {
+		stored, err := store.Begin(ctx, "anonymous|key-1", "fingerprint", time.Minute)
+		Expect(err).To(BeNil())
+		Expect(stored).To(BeNil())
+
+		_, err = store.Begin(ctx, "anonymous|key-1", "fingerprint", time.Minute)
+		Expect(err).To(Equal(api.ErrIdempotencyKeyInFlight))
+
+		response := api.IdempotentResponse{
+			Status: http.StatusCreated,
+			Header: http.Header{"Content-Type": []string{"application/json"}},
+			Body:   []byte(`{"id":1}`),
+		}
+		Expect(store.Complete(ctx, "anonymous|key-1", response, time.Hour)).To(Succeed())
+
+		stored, err = store.Begin(ctx, "anonymous|key-1", "fingerprint", time.Minute)
+		Expect(err).To(BeNil())
+		Expect(*stored).To(Equal(response))
+
+		_, err = store.Begin(ctx, "anonymous|key-1", "other", time.Minute)
+		Expect(err).To(Equal(api.ErrIdempotencyKeyReused))
+	})
+
+	It("should release incomplete keys", func() {
+		_, err := store.Begin(ctx, "anonymous|key-2", "fingerprint", time.Minute)
+		Expect(err).To(BeNil())
+		Expect(store.Release(ctx, "anonymous|key-2")).To(Succeed())
+
+		stored, err := store.Begin(ctx, "anonymous|key-2", "fingerprint", time.Minute)
+		Expect(err).To(BeNil())
+		Expect(stored).To(BeNil())
+	})
+
+	It("should prune expired keys", func() {
+		Expect(store.Prune(ctx)).To(Succeed())
+	})
+})
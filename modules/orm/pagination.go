@@ -0,0 +1,187 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/wgentry22/agora/modules/api"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+	ErrUnknownCursorColumn = func(column string) error {
+		return fmt.Errorf("cannot build cursor: unknown column `%s`", column)
+	}
+)
+
+func FilterScope(filters []api.Filter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, filter := range filters {
+			db = db.Where(filterExpression(filter))
+		}
+
+		return db
+	}
+}
+
+func ListScope(opts api.ListOptions) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Scopes(FilterScope(opts.Filters))
+
+		backward := opts.Cursor != nil && opts.Cursor.Backward
+
+		if opts.Cursor != nil {
+			db = db.Where(keysetExpression(opts.Sort, opts.Cursor.Values, backward))
+		}
+
+		for _, field := range opts.Sort {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc != backward})
+		}
+
+		return db.Limit(opts.Limit + 1).Offset(opts.Offset())
+	}
+}
+
+func FindPage(db *gorm.DB, opts api.ListOptions, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) (api.Page, error) {
+	page := api.Page{Items: dest, Limit: opts.Limit}
+	if !opts.Keyset {
+		page.Page = opts.Page
+	}
+
+	if opts.IncludeTotal {
+		var total int64
+		if err := db.Model(dest).Scopes(scopes...).Scopes(FilterScope(opts.Filters)).Count(&total).Error; err != nil {
+			return page, err
+		}
+
+		page.Total = &total
+	}
+
+	tx := db.Scopes(scopes...).Scopes(ListScope(opts)).Find(dest)
+	if tx.Error != nil {
+		return page, tx.Error
+	}
+
+	items := reflect.ValueOf(dest).Elem()
+
+	hasMore := items.Len() > opts.Limit
+	if hasMore {
+		items.Set(items.Slice(0, opts.Limit))
+	}
+
+	if !opts.Keyset {
+		page.HasMore = hasMore
+
+		return page, nil
+	}
+
+	backward := opts.Cursor != nil && opts.Cursor.Backward
+	if backward {
+		reverseSlice(items)
+	}
+
+	if items.Len() == 0 {
+		return page, nil
+	}
+
+	if backward || hasMore {
+		values, err := cursorValues(tx, opts.Sort, items.Index(items.Len()-1))
+		if err != nil {
+			return page, err
+		}
+
+		page.NextCursor = api.EncodeCursor(api.Cursor{Values: values})
+	}
+
+	if opts.Cursor != nil && (!backward || hasMore) {
+		values, err := cursorValues(tx, opts.Sort, items.Index(0))
+		if err != nil {
+			return page, err
+		}
+
+		page.PrevCursor = api.EncodeCursor(api.Cursor{Values: values, Backward: true})
+	}
+
+	page.HasMore = page.NextCursor != ""
+
+	return page, nil
+}
+
+func filterExpression(filter api.Filter) clause.Expression {
+	column := clause.Column{Name: filter.Column}
+	value := filter.Values[0]
+
+	switch filter.Operator {
+	case api.FilterNe:
+		return clause.Neq{Column: column, Value: value}
+	case api.FilterGt:
+		return clause.Gt{Column: column, Value: value}
+	case api.FilterGte:
+		return clause.Gte{Column: column, Value: value}
+	case api.FilterLt:
+		return clause.Lt{Column: column, Value: value}
+	case api.FilterLte:
+		return clause.Lte{Column: column, Value: value}
+	case api.FilterLike:
+		return clause.Expr{SQL: `? LIKE ? ESCAPE '\'`, Vars: []interface{}{column, "%" + likeEscaper.Replace(value) + "%"}}
+	case api.FilterIn:
+		values := make([]interface{}, len(filter.Values))
+		for i, v := range filter.Values {
+			values[i] = v
+		}
+
+		return clause.IN{Column: column, Values: values}
+	default:
+		return clause.Eq{Column: column, Value: value}
+	}
+}
+
+func keysetExpression(sort []api.SortField, values []interface{}, backward bool) clause.Expression {
+	alternatives := make([]clause.Expression, 0, len(sort))
+
+	for i, field := range sort {
+		conditions := make([]clause.Expression, 0, i+1)
+
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: clause.Column{Name: sort[j].Column}, Value: values[j]})
+		}
+
+		column := clause.Column{Name: field.Column}
+		if field.Desc != backward {
+			conditions = append(conditions, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: column, Value: values[i]})
+		}
+
+		alternatives = append(alternatives, clause.And(conditions...))
+	}
+
+	return clause.Or(alternatives...)
+}
+
+func cursorValues(tx *gorm.DB, sort []api.SortField, item reflect.Value) ([]interface{}, error) {
+	values := make([]interface{}, len(sort))
+
+	for i, field := range sort {
+		schemaField := tx.Statement.Schema.LookUpField(field.Column)
+		if schemaField == nil {
+			return nil, ErrUnknownCursorColumn(field.Column)
+		}
+
+		values[i], _ = schemaField.ValueOf(reflect.Indirect(item))
+	}
+
+	return values, nil
+}
+
+func reverseSlice(items reflect.Value) {
+	swap := reflect.Swapper(items.Interface())
+
+	for i, j := 0, items.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
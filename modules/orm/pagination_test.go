@@ -0,0 +1,115 @@
+package orm_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/orm"
+	"gorm.io/gorm"
+)
+
+type pagedThing struct {
+	ID     uint `gorm:"primaryKey"`
+	Name   string
+	Status string
+}
+
+var _ = Describe("Pagination", func() {
+	var (
+		db *gorm.DB
+	)
+
+	JustBeforeEach(func() {
+		orm.UseConfig(mustParseConfig())
+		db = orm.Get()
+
+		Expect(db.Migrator().DropTable(&pagedThing{})).To(Succeed())
+		Expect(db.AutoMigrate(&pagedThing{})).To(Succeed())
+
+		for i := 1; i <= 5; i++ {
+			status := "active"
+			if i%2 == 0 {
+				status = "archived"
+			}
+
+			Expect(db.Create(&pagedThing{Name: fmt.Sprintf("thing-%d", i), Status: status}).Error).To(Succeed())
+		}
+	})
+
+	It("should page with offsets and count the total", func() {
+		var things []pagedThing
+
+		page, err := orm.FindPage(db, api.ListOptions{
+			Page:         2,
+			Limit:        2,
+			Sort:         []api.SortField{{Column: "id"}},
+			IncludeTotal: true,
+		}, &things)
+		Expect(err).To(BeNil())
+
+		Expect(things).To(HaveLen(2))
+		Expect(things[0].ID).To(Equal(uint(3)))
+		Expect(*page.Total).To(Equal(int64(5)))
+		Expect(page.HasMore).To(BeTrue())
+	})
+
+	It("should apply filters", func() {
+		var things []pagedThing
+
+		_, err := orm.FindPage(db, api.ListOptions{
+			Page:    1,
+			Limit:   10,
+			Filters: []api.Filter{{Column: "status", Operator: api.FilterEq, Values: []string{"archived"}}},
+		}, &things)
+		Expect(err).To(BeNil())
+
+		Expect(things).To(HaveLen(2))
+	})
+
+	It("should match like filters literally", func() {
+		Expect(db.Create(&pagedThing{Name: "50%_off", Status: "active"}).Error).To(Succeed())
+		Expect(db.Create(&pagedThing{Name: "500 off", Status: "active"}).Error).To(Succeed())
+
+		var things []pagedThing
+
+		_, err := orm.FindPage(db, api.ListOptions{
+			Page:    1,
+			Limit:   10,
+			Filters: []api.Filter{{Column: "name", Operator: api.FilterLike, Values: []string{"%_"}}},
+		}, &things)
+		Expect(err).To(BeNil())
+
+		Expect(things).To(HaveLen(1))
+		Expect(things[0].Name).To(Equal("50%_off"))
+	})
+
+	It("should walk forwards and backwards with cursors", func() {
+		opts := api.ListOptions{Limit: 2, Keyset: true, Sort: []api.SortField{{Column: "name", Desc: true}, {Column: "id"}}}
+
+		var first []pagedThing
+		page, err := orm.FindPage(db, opts, &first)
+		Expect(err).To(BeNil())
+		Expect(first[0].Name).To(Equal("thing-5"))
+		Expect(page.PrevCursor).To(BeEmpty())
+		Expect(page.NextCursor).NotTo(BeEmpty())
+
+		opts.Cursor, err = api.DecodeCursor(page.NextCursor)
+		Expect(err).To(BeNil())
+
+		var second []pagedThing
+		page, err = orm.FindPage(db, opts, &second)
+		Expect(err).To(BeNil())
+		Expect(second[0].Name).To(Equal("thing-3"))
+		Expect(page.PrevCursor).NotTo(BeEmpty())
+
+		opts.Cursor, err = api.DecodeCursor(page.PrevCursor)
+		Expect(err).To(BeNil())
+
+		var back []pagedThing
+		_, err = orm.FindPage(db, opts, &back)
+		Expect(err).To(BeNil())
+		Expect(back).To(Equal(first))
+	})
+})
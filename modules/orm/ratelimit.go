@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/types/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFailedToMigrateRateLimitBuckets = errors.New("failed to migrate rate limit buckets")
+)
+
+type rateLimitBucket struct {
+	Key       string `gorm:"primaryKey"`
+	Tokens    float64
+	Refilled  *time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+func (rateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
+
+type RateLimitStore struct {
+	db *gorm.DB
+}
+
+func NewRateLimitStore(db *gorm.DB) *RateLimitStore {
+	if err := db.AutoMigrate(&rateLimitBucket{}); err != nil {
+		panic(errwrap.Wrap(ErrFailedToMigrateRateLimitBuckets, err))
+	}
+
+	return &RateLimitStore{db}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, rule config.RateLimitRule) (result api.RateLimitResult, err error) {
+	now := time.Now()
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rateLimitBucket{Key: key, ExpiresAt: now}).Error; err != nil {
+			return err
+		}
+
+		var stored rateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stored, "key = ?", key).Error; err != nil {
+			return err
+		}
+
+		bucket := api.TokenBucket{Tokens: stored.Tokens}
+		if stored.Refilled != nil {
+			bucket.Updated = *stored.Refilled
+		}
+
+		bucket, result = bucket.Take(now, rule)
+
+		return tx.Model(&stored).Updates(map[string]interface{}{
+			"tokens":     bucket.Tokens,
+			"refilled":   bucket.Updated,
+			"expires_at": now.Add(result.Reset),
+		}).Error
+	})
+
+	return result, err
+}
+
+func (s *RateLimitStore) Prune(ctx context.Context) error {
+	return s.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&rateLimitBucket{}).Error
+}
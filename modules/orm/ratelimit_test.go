@@ -0,0 +1,43 @@
+package orm_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/orm"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("RateLimitStore", func() {
+	var (
+		store *orm.RateLimitStore
+		rule  = config.RateLimitRule{Requests: 1, Window: time.Minute, Burst: 1, Key: config.RateLimitKeyIP}
+	)
+
+	JustBeforeEach(func() {
+		orm.UseConfig(mustParseConfig())
+		store = orm.NewRateLimitStore(orm.Get())
+	})
+
+	It("should share a token bucket per key", func() {
+		first, err := store.Take(context.Background(), "global|ip:10.0.0.1", rule)
+		Expect(err).To(BeNil())
+		Expect(first.Allowed).To(BeTrue())
+		Expect(first.Remaining).To(Equal(0))
+
+		second, err := store.Take(context.Background(), "global|ip:10.0.0.1", rule)
+		Expect(err).To(BeNil())
+		Expect(second.Allowed).To(BeFalse())
+		Expect(second.RetryAfter).To(BeNumerically(">", 0))
+
+		other, err := store.Take(context.Background(), "global|ip:10.0.0.2", rule)
+		Expect(err).To(BeNil())
+		Expect(other.Allowed).To(BeTrue())
+	})
+
+	It("should prune expired buckets", func() {
+		Expect(store.Prune(context.Background())).To(Succeed())
+	})
+})
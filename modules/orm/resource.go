@@ -0,0 +1,347 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wgentry22/agora/modules/api"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+type Operation int8
+
+const (
+	OperationList Operation = iota
+	OperationGet
+	OperationCreate
+	OperationUpdate
+	OperationDelete
+)
+
+var (
+	ErrInvalidResourceModel = func(model interface{}) error {
+		return fmt.Errorf("resource model must be a struct or pointer to a struct, got `%T`", model)
+	}
+	ErrUnknownWritableField = func(model, field string) error {
+		return fmt.Errorf("resource model `%s` has no writable field `%s`", model, field)
+	}
+	operationDisplay = []string{"list", "get", "create", "update", "delete"}
+	allOperations    = []Operation{OperationList, OperationGet, OperationCreate, OperationUpdate, OperationDelete}
+)
+
+func (o Operation) String() string {
+	return operationDisplay[o]
+}
+
+type ResourceHook func(c *gin.Context, record interface{}) error
+
+type ResourceOptions struct {
+	List       api.ListSpec
+	IDColumn   string
+	Operations []Operation
+	Writable   []string
+	Authorize  func(c *gin.Context, op Operation) error
+	Hooks      map[Operation]ResourceHook
+}
+
+type resource struct {
+	db        *gorm.DB
+	modelType reflect.Type
+	writable  []*schema.Field
+	creatable []*schema.Field
+	opts      ResourceOptions
+}
+
+func NewResourceController(uri string, model interface{}, db *gorm.DB, opts ResourceOptions) api.Controller {
+	modelType := reflect.TypeOf(model)
+	for modelType != nil && modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+
+	if modelType == nil || modelType.Kind() != reflect.Struct {
+		panic(ErrInvalidResourceModel(model))
+	}
+
+	if opts.IDColumn == "" {
+		opts.IDColumn = "id"
+	}
+
+	if len(opts.List.DefaultSort) == 0 {
+		opts.List.DefaultSort = []api.SortField{{Field: opts.IDColumn, Column: opts.IDColumn}}
+	}
+
+	if len(opts.Operations) == 0 {
+		opts.Operations = allOperations
+	}
+
+	example := reflect.New(modelType).Interface()
+	examples := reflect.MakeSlice(reflect.SliceOf(modelType), 0, 0).Interface()
+
+	writable, creatable := resourceFields(db, example, opts)
+
+	r := &resource{db: db, modelType: modelType, writable: writable, creatable: creatable, opts: opts}
+
+	controller := api.NewController(uri)
+
+	for _, op := range opts.Operations {
+		switch op {
+		case OperationList:
+			controller.Register(api.NewGETRoute("", r.handle(op, r.list)).
+				WithSummary(fmt.Sprintf("List %s", modelType.Name())).
+				WithResponse(http.StatusOK, examples))
+		case OperationGet:
+			controller.Register(api.NewGETRoute("/:id", r.handle(op, r.get)).
+				WithSummary(fmt.Sprintf("Get %s", modelType.Name())).
+				WithResponse(http.StatusOK, example))
+		case OperationCreate:
+			controller.Register(api.NewPOSTRoute("", r.handle(op, r.create)).
+				WithSummary(fmt.Sprintf("Create %s", modelType.Name())).
+				WithRequest(example).
+				WithResponse(http.StatusCreated, example))
+		case OperationUpdate:
+			controller.Register(api.NewPUTRoute("/:id", r.handle(op, r.update)).
+				WithSummary(fmt.Sprintf("Update %s", modelType.Name())).
+				WithRequest(example).
+				WithResponse(http.StatusOK, example))
+		case OperationDelete:
+			controller.Register(api.NewDELETERoute("/:id", r.handle(op, r.delete)).
+				WithSummary(fmt.Sprintf("Delete %s", modelType.Name())).
+				WithResponse(http.StatusNoContent, nil))
+		}
+	}
+
+	return controller
+}
+
+func (r *resource) handle(op Operation, handler func(c *gin.Context, tx *gorm.DB) error) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if r.opts.Authorize != nil {
+			if err := r.opts.Authorize(c, op); err != nil {
+				api.RenderError(c, err)
+
+				return
+			}
+		}
+
+		if err := handler(c, r.db.WithContext(c.Request.Context())); err != nil {
+			api.RenderError(c, err)
+		}
+	}
+}
+
+func (r *resource) hook(c *gin.Context, op Operation, record interface{}) error {
+	if hook, ok := r.opts.Hooks[op]; ok {
+		return hook(c, record)
+	}
+
+	return nil
+}
+
+func (r *resource) list(c *gin.Context, tx *gorm.DB) error {
+	opts, err := api.ParseListOptions(c, r.opts.List)
+	if err != nil {
+		return err
+	}
+
+	records := reflect.New(reflect.SliceOf(r.modelType))
+	records.Elem().Set(reflect.MakeSlice(reflect.SliceOf(r.modelType), 0, opts.Limit))
+
+	page, err := FindPage(tx, opts, records.Interface())
+	if err != nil {
+		return err
+	}
+
+	if err := r.hook(c, OperationList, records.Interface()); err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusOK, page)
+
+	return nil
+}
+
+func (r *resource) get(c *gin.Context, tx *gorm.DB) error {
+	record, err := r.find(c, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := r.hook(c, OperationGet, record); err != nil {
+		return err
+	}
+
+	api.JSONWithETag(c, http.StatusOK, record)
+
+	return nil
+}
+
+func (r *resource) create(c *gin.Context, tx *gorm.DB) error {
+	record := reflect.New(r.modelType).Interface()
+
+	if err := r.bindFields(c, record, r.creatable); err != nil {
+		return err
+	}
+
+	if err := r.hook(c, OperationCreate, record); err != nil {
+		return err
+	}
+
+	if err := tx.Create(record).Error; err != nil {
+		return err
+	}
+
+	c.JSON(http.StatusCreated, record)
+
+	return nil
+}
+
+func (r *resource) update(c *gin.Context, tx *gorm.DB) error {
+	record, err := r.find(c, tx)
+	if err != nil {
+		return err
+	}
+
+	etag, err := api.ETag(record)
+	if err != nil {
+		return err
+	}
+
+	if !api.CheckPreconditions(c, etag) {
+		return nil
+	}
+
+	if err := r.bindFields(c, record, r.writable); err != nil {
+		return err
+	}
+
+	if err := r.hook(c, OperationUpdate, record); err != nil {
+		return err
+	}
+
+	columns := make([]string, len(r.writable))
+	for i, field := range r.writable {
+		columns[i] = field.DBName
+	}
+
+	if len(columns) > 0 {
+		if err := tx.Model(record).Where(r.idClause(c)).Select(columns).Updates(record).Error; err != nil {
+			return err
+		}
+	}
+
+	if etag, err := api.ETag(record); err == nil {
+		c.Header(api.ETagHeader, etag)
+	}
+
+	c.JSON(http.StatusOK, record)
+
+	return nil
+}
+
+func (r *resource) bindFields(c *gin.Context, record interface{}, fields []*schema.Field) error {
+	current := reflect.ValueOf(record).Elem()
+
+	dto := reflect.New(r.modelType)
+	dto.Elem().Set(current)
+
+	if err := api.BindJSON(c, dto.Interface()); err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		value, _ := field.ValueOf(dto.Elem())
+		if err := field.Set(current, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *resource) delete(c *gin.Context, tx *gorm.DB) error {
+	record, err := r.find(c, tx)
+	if err != nil {
+		return err
+	}
+
+	etag, err := api.ETag(record)
+	if err != nil {
+		return err
+	}
+
+	if !api.CheckPreconditions(c, etag) {
+		return nil
+	}
+
+	if err := r.hook(c, OperationDelete, record); err != nil {
+		return err
+	}
+
+	if err := tx.Delete(record).Error; err != nil {
+		return err
+	}
+
+	c.Status(http.StatusNoContent)
+
+	return nil
+}
+
+func (r *resource) find(c *gin.Context, tx *gorm.DB) (interface{}, error) {
+	record := reflect.New(r.modelType).Interface()
+
+	found := tx.Where(r.idClause(c)).First(record)
+	if errors.Is(found.Error, gorm.ErrRecordNotFound) {
+		return nil, api.ErrNotFound(fmt.Sprintf("%s `%s` not found", r.modelType.Name(), c.Param("id"))).WithCause(found.Error)
+	}
+
+	return record, found.Error
+}
+
+func (r *resource) idClause(c *gin.Context) clause.Eq {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: r.opts.IDColumn}, Value: c.Param("id")}
+}
+
+func resourceFields(db *gorm.DB, model interface{}, opts ResourceOptions) (writable, creatable []*schema.Field) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		panic(err)
+	}
+
+	for _, name := range opts.Writable {
+		field := stmt.Schema.LookUpField(name)
+		if field == nil || field.DBName == "" || !field.Updatable {
+			panic(ErrUnknownWritableField(stmt.Schema.Name, name))
+		}
+
+		writable = append(writable, field)
+	}
+
+	for _, field := range stmt.Schema.Fields {
+		if !isAssignable(field, opts) {
+			continue
+		}
+
+		if field.Updatable && len(opts.Writable) == 0 {
+			writable = append(writable, field)
+		}
+
+		if field.Creatable && !field.Updatable {
+			creatable = append(creatable, field)
+		}
+	}
+
+	return writable, append(creatable, writable...)
+}
+
+func isAssignable(field *schema.Field, opts ResourceOptions) bool {
+	if field.DBName == "" || field.PrimaryKey || field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 {
+		return false
+	}
+
+	return field.DBName != opts.IDColumn && field.DBName != tenantColumn
+}
@@ -0,0 +1,176 @@
+package orm_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/orm"
+	"github.com/wgentry22/agora/types/config"
+)
+
+type widget struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" validate:"required"`
+}
+
+type gadget struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Owner     string    `json:"owner" gorm:"<-:create"`
+	Name      string    `json:"name" validate:"required"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var _ = Describe("ResourceController", func() {
+	var (
+		router api.Router
+	)
+
+	perform := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	JustBeforeEach(func() {
+		orm.UseConfig(mustParseConfig())
+		db := orm.Get()
+
+		Expect(db.Migrator().DropTable(&widget{})).To(Succeed())
+		Expect(db.AutoMigrate(&widget{})).To(Succeed())
+
+		router = api.NewRouter(config.API{Port: 8123, PathPrefix: "/api"})
+		router.Register(orm.NewResourceController("/widgets", widget{}, db, orm.ResourceOptions{
+			List: api.ListSpec{Sortable: []string{"name"}},
+			Authorize: func(c *gin.Context, op orm.Operation) error {
+				if op == orm.OperationDelete && c.GetHeader("X-Role") != "admin" {
+					return api.ErrForbidden("only admins may delete widgets")
+				}
+
+				return nil
+			},
+			Hooks: map[orm.Operation]orm.ResourceHook{
+				orm.OperationCreate: func(c *gin.Context, record interface{}) error {
+					if record.(*widget).Name == "forbidden" {
+						return api.ErrConflict("name is reserved").WithCause(errors.New("reserved"))
+					}
+
+					return nil
+				},
+			},
+		}))
+	})
+
+	It("should create, read, update, list and delete records", func() {
+		created := perform(http.MethodPost, "/api/widgets", `{"name":"sprocket"}`)
+		Expect(created.Code).To(Equal(http.StatusCreated))
+		Expect(created.Body.String()).To(Equal(`{"id":1,"name":"sprocket"}`))
+
+		Expect(perform(http.MethodGet, "/api/widgets/1", "").Body.String()).To(Equal(`{"id":1,"name":"sprocket"}`))
+
+		updated := perform(http.MethodPut, "/api/widgets/1", `{"id":99,"name":"cog"}`)
+		Expect(updated.Code).To(Equal(http.StatusOK))
+		Expect(updated.Body.String()).To(Equal(`{"id":1,"name":"cog"}`))
+
+		listed := perform(http.MethodGet, "/api/widgets?total=true", "")
+		Expect(listed.Body.String()).To(Equal(`{"items":[{"id":1,"name":"cog"}],"page":1,"limit":20,"total":1,"hasMore":false}`))
+
+		Expect(perform(http.MethodDelete, "/api/widgets/1", "").Code).To(Equal(http.StatusForbidden))
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/widgets/1", nil)
+		req.Header.Set("X-Role", "admin")
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+		Expect(rr.Code).To(Equal(http.StatusNoContent))
+
+		Expect(perform(http.MethodGet, "/api/widgets/1", "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should reject stale updates using ETags", func() {
+		Expect(perform(http.MethodPost, "/api/widgets", `{"name":"sprocket"}`).Code).To(Equal(http.StatusCreated))
+
+		etag := perform(http.MethodGet, "/api/widgets/1", "").Header().Get(api.ETagHeader)
+		Expect(etag).ToNot(BeEmpty())
+
+		conditional := func(method, body, ifMatch string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, "/api/widgets/1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", ifMatch)
+
+			rr := httptest.NewRecorder()
+			router.Handler().ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		updated := conditional(http.MethodPut, `{"name":"cog"}`, etag)
+		Expect(updated.Code).To(Equal(http.StatusOK))
+		Expect(updated.Header().Get(api.ETagHeader)).ToNot(Equal(etag))
+
+		stale := conditional(http.MethodPut, `{"name":"gear"}`, etag)
+		Expect(stale.Code).To(Equal(http.StatusPreconditionFailed))
+		Expect(perform(http.MethodGet, "/api/widgets/1", "").Body.String()).To(Equal(`{"id":1,"name":"cog"}`))
+	})
+
+	It("should validate request bodies", func() {
+		rr := perform(http.MethodPost, "/api/widgets", `{}`)
+
+		Expect(rr.Code).To(Equal(http.StatusUnprocessableEntity))
+		Expect(rr.Header().Get("Content-Type")).To(ContainSubstring(api.ProblemContentType))
+	})
+
+	It("should surface hook errors as problems", func() {
+		Expect(perform(http.MethodPost, "/api/widgets", `{"name":"forbidden"}`).Code).To(Equal(http.StatusConflict))
+	})
+
+	It("should only update writable fields", func() {
+		db := orm.Get()
+
+		Expect(db.Migrator().DropTable(&gadget{})).To(Succeed())
+		Expect(db.AutoMigrate(&gadget{})).To(Succeed())
+
+		router.Register(orm.NewResourceController("/gadgets", gadget{}, db, orm.ResourceOptions{}))
+
+		Expect(perform(http.MethodPost, "/api/gadgets", `{"owner":"alice","name":"cog"}`).Code).To(Equal(http.StatusCreated))
+
+		updated := perform(http.MethodPut, "/api/gadgets/1", `{"id":9,"owner":"mallory","name":"gear","createdAt":"2000-01-01T00:00:00Z"}`)
+		Expect(updated.Code).To(Equal(http.StatusOK))
+
+		for _, body := range []string{updated.Body.String(), perform(http.MethodGet, "/api/gadgets/1", "").Body.String()} {
+			Expect(body).To(ContainSubstring(`"id":1,"owner":"alice","name":"gear"`))
+			Expect(body).ToNot(ContainSubstring("2000-01-01"))
+		}
+	})
+
+	It("should ignore primary keys and timestamps on create", func() {
+		db := orm.Get()
+
+		Expect(db.Migrator().DropTable(&gadget{})).To(Succeed())
+		Expect(db.AutoMigrate(&gadget{})).To(Succeed())
+
+		router.Register(orm.NewResourceController("/gadgets", gadget{}, db, orm.ResourceOptions{}))
+
+		created := perform(http.MethodPost, "/api/gadgets", `{"id":7,"owner":"alice","name":"cog","createdAt":"2000-01-01T00:00:00Z"}`)
+		Expect(created.Code).To(Equal(http.StatusCreated))
+		Expect(created.Body.String()).To(ContainSubstring(`"id":1,"owner":"alice","name":"cog"`))
+		Expect(created.Body.String()).ToNot(ContainSubstring("2000-01-01"))
+
+		Expect(perform(http.MethodGet, "/api/gadgets/7", "").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should panic on unknown writable fields", func() {
+		Expect(func() {
+			orm.NewResourceController("/gadgets", gadget{}, orm.Get(), orm.ResourceOptions{Writable: []string{"color"}})
+		}).To(Panic())
+	})
+})
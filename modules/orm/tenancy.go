@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/tenancy"
+	"github.com/wgentry22/agora/types/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFailedToRegisterTenancyCallbacks = errors.New("failed to register tenancy callbacks")
+	tenancyCallbackName                 = "agora:tenancy"
+	tenantColumn                        string
+)
+
+func UseTenancy(conf config.Tenancy) {
+	db := Get()
+
+	m.Lock()
+	defer m.Unlock()
+
+	if err := RegisterTenancyCallbacks(db, conf); err != nil {
+		panic(err)
+	}
+
+	tenantColumn = conf.Column
+}
+
+func RegisterTenancyCallbacks(db *gorm.DB, conf config.Tenancy) error {
+	stamp := stampTenant(conf.Column)
+	filter := filterTenant(conf.Column)
+
+	callbacks := db.Callback()
+
+	errs := []error{
+		callbacks.Create().Before("gorm:create").Register(tenancyCallbackName, stamp),
+		callbacks.Query().Before("gorm:query").Register(tenancyCallbackName, filter),
+		callbacks.Update().Before("gorm:update").Register(tenancyCallbackName, filter),
+		callbacks.Delete().Before("gorm:delete").Register(tenancyCallbackName, filter),
+		callbacks.Row().Before("gorm:row").Register(tenancyCallbackName, filter),
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return errwrap.Wrap(ErrFailedToRegisterTenancyCallbacks, err)
+		}
+	}
+
+	return nil
+}
+
+func TenantScope(ctx context.Context, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tenant, ok := tenancy.FromContext(ctx)
+		if !ok {
+			return db
+		}
+
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: tenant})
+	}
+}
+
+func hasTenantColumn(db *gorm.DB, column string) bool {
+	return db.Statement.Schema != nil && db.Statement.Schema.LookUpField(column) != nil
+}
+
+func stampTenant(column string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		tenant, ok := tenancy.FromContext(db.Statement.Context)
+		if !ok || !hasTenantColumn(db, column) {
+			return
+		}
+
+		db.Statement.SetColumn(column, tenant, true)
+	}
+}
+
+func filterTenant(column string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		tenant, ok := tenancy.FromContext(db.Statement.Context)
+		if !ok || !hasTenantColumn(db, column) {
+			return
+		}
+
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: tenant},
+		}})
+	}
+}
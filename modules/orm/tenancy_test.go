@@ -0,0 +1,95 @@
+package orm_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/orm"
+	"github.com/wgentry22/agora/modules/tenancy"
+	"github.com/wgentry22/agora/types/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type tenantThing struct {
+	ID       uint `gorm:"primaryKey"`
+	TenantID string
+	Name     string
+}
+
+type sharedThing struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+var _ = Describe("Tenancy", func() {
+	var (
+		db  *gorm.DB
+		ctx context.Context
+	)
+
+	dryRun := func(ctx context.Context) *gorm.DB {
+		return db.WithContext(ctx).Session(&gorm.Session{DryRun: true})
+	}
+
+	BeforeEach(func() {
+		var err error
+		db, err = gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test sslmode=disable"}), &gorm.Config{
+			DryRun:               true,
+			DisableAutomaticPing: true,
+		})
+		Expect(err).To(BeNil())
+		Expect(orm.RegisterTenancyCallbacks(db, config.Tenancy{Column: "tenant_id"})).To(Succeed())
+
+		ctx = tenancy.WithTenant(context.Background(), "acme")
+	})
+
+	It("should stamp the tenant on create", func() {
+		stmt := dryRun(ctx).Create(&tenantThing{Name: "cog"}).Statement
+
+		Expect(stmt.SQL.String()).To(ContainSubstring(`"tenant_id"`))
+		Expect(stmt.Vars).To(ContainElement("acme"))
+	})
+
+	It("should filter queries, updates and deletes by tenant", func() {
+		var things []tenantThing
+
+		query := dryRun(ctx).Find(&things).Statement
+		Expect(query.SQL.String()).To(ContainSubstring(`WHERE "tenant_things"."tenant_id" = $1`))
+		Expect(query.Vars).To(Equal([]interface{}{"acme"}))
+
+		update := dryRun(ctx).Model(&tenantThing{ID: 1}).Update("name", "sprocket").Statement
+		Expect(update.SQL.String()).To(ContainSubstring(`"tenant_things"."tenant_id" = `))
+		Expect(update.Vars).To(ContainElement("acme"))
+
+		del := dryRun(ctx).Delete(&tenantThing{ID: 1}).Statement
+		Expect(del.SQL.String()).To(ContainSubstring(`"tenant_things"."tenant_id" = `))
+		Expect(del.Vars).To(ContainElement("acme"))
+	})
+
+	It("should leave requests without a tenant and models without the column alone", func() {
+		var things []tenantThing
+		Expect(dryRun(context.Background()).Find(&things).Statement.SQL.String()).ToNot(ContainSubstring("tenant_id"))
+
+		var shared []sharedThing
+		Expect(dryRun(ctx).Find(&shared).Statement.SQL.String()).ToNot(ContainSubstring("tenant_id"))
+	})
+
+	It("should scope queries explicitly with TenantScope", func() {
+		plain, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost user=test dbname=test sslmode=disable"}), &gorm.Config{
+			DryRun:               true,
+			DisableAutomaticPing: true,
+		})
+		Expect(err).To(BeNil())
+
+		var things []tenantThing
+		stmt := plain.Scopes(orm.TenantScope(ctx, "tenant_id")).Find(&things).Statement
+
+		Expect(stmt.SQL.String()).To(ContainSubstring(`WHERE "tenant_things"."tenant_id" = $1`))
+		Expect(stmt.Vars).To(Equal([]interface{}{"acme"}))
+
+		stmt = plain.Scopes(orm.TenantScope(context.Background(), "tenant_id")).Find(&things).Statement
+		Expect(stmt.SQL.String()).ToNot(ContainSubstring("tenant_id"))
+	})
+})
@@ -0,0 +1,131 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wgentry22/agora/modules/heartbeat"
+	"github.com/wgentry22/agora/types/config"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type healthServer struct {
+	info      config.Info
+	interval  time.Duration
+	mu        sync.RWMutex
+	draining  bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newHealthServer(info config.Info, interval time.Duration) *healthServer {
+	return &healthServer{
+		info:     info,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus := h.status(ctx, req.GetService())
+	if servingStatus == healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service `%s`", req.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+
+	for {
+		current := h.status(stream.Context(), req.GetService())
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+
+			last = current
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-h.done:
+			if last != healthpb.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+			}
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *healthServer) shutdown() {
+	h.closeOnce.Do(func() {
+		h.mu.Lock()
+		h.draining = true
+		h.mu.Unlock()
+
+		close(h.done)
+	})
+}
+
+func (h *healthServer) status(ctx context.Context, service string) healthpb.HealthCheckResponse_ServingStatus {
+	h.mu.RLock()
+	draining := h.draining
+	h.mu.RUnlock()
+
+	if draining {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	withTimeout, cancel := context.WithTimeout(ctx, h.interval)
+	defer cancel()
+
+	pulses := heartbeat.CollectPulses(withTimeout)
+
+	if service == "" {
+		response := heartbeat.NewHealthCheckResponse(h.info, pulses)
+		if response.HTTPStatus() == http.StatusServiceUnavailable {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	for _, pulse := range pulses {
+		if pulse.Component == service {
+			return servingStatus(worstStatus(pulse))
+		}
+	}
+
+	return healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+}
+
+func worstStatus(pulse heartbeat.Pulse) heartbeat.HealthCheckStatus {
+	worst := pulse.Status
+	for _, dep := range pulse.Dependencies {
+		if depStatus := worstStatus(dep); depStatus == heartbeat.StatusCritical {
+			worst = depStatus
+		}
+	}
+
+	return worst
+}
+
+func servingStatus(healthStatus heartbeat.HealthCheckStatus) healthpb.HealthCheckResponse_ServingStatus {
+	if healthStatus == heartbeat.StatusCritical {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey int
+
+const (
+	subjectKey contextKey = iota
+)
+
+var (
+	requestIDMetadata     = strings.ToLower(api.RequestIDHeader)
+	authorizationMetadata = "authorization"
+	healthServicePrefix   = "/grpc.health.v1.Health/"
+	reflectionPrefix      = "/grpc.reflection.v1alpha.ServerReflection/"
+	clientErrorCodes      = map[codes.Code]bool{
+		codes.Canceled:           true,
+		codes.InvalidArgument:    true,
+		codes.NotFound:           true,
+		codes.AlreadyExists:      true,
+		codes.PermissionDenied:   true,
+		codes.Unauthenticated:    true,
+		codes.FailedPrecondition: true,
+		codes.OutOfRange:         true,
+		codes.ResourceExhausted:  true,
+	}
+)
+
+func ContextWithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, subjectKey, subject)
+}
+
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(subjectKey).(string)
+
+	return subject, ok && subject != ""
+}
+
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func withContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
+
+func requestIDContext(ctx context.Context) context.Context {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 && len(values[0]) <= 128 {
+			id = values[0]
+		}
+	}
+
+	if id == "" {
+		id = api.NewRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+
+	return logg.ContextWithRequestID(ctx, id)
+}
+
+func requestIDUnary(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(requestIDContext(ctx), req)
+}
+
+func requestIDStream(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, withContext(ss, requestIDContext(ss.Context())))
+}
+
+func recovered(ctx context.Context, fullMethod string, r interface{}) error {
+	err, isErr := r.(error)
+	if !isErr {
+		err = fmt.Errorf("%v", r)
+	}
+
+	logg.Root().
+		WithContext(ctx).
+		WithField("grpc_method", fullMethod).
+		WithError(err).
+		Error("Recovered from panic")
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+func recoveryUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, info.FullMethod, r)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoveryStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ss.Context(), info.FullMethod, r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func logCall(ctx context.Context, fullMethod string, start time.Time, err error) {
+	code := status.Code(err)
+
+	entry := logg.Root().
+		WithContext(ctx).
+		WithField("grpc_method", fullMethod).
+		WithField("grpc_code", code.String()).
+		WithField("latency_ms", float64(time.Since(start).Microseconds())/1000)
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry = entry.WithField("peer", p.Addr.String())
+	}
+
+	switch {
+	case code == codes.OK:
+		entry.Info("RPC completed")
+	case clientErrorCodes[code]:
+		entry.WithError(err).Warn("RPC completed")
+	default:
+		entry.WithError(err).Error("RPC completed")
+	}
+}
+
+func loggingUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+
+	return resp, err
+}
+
+func loggingStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, start, err)
+
+	return err
+}
+
+type authenticator struct {
+	conf     config.GRPC
+	verifier auth.TokenVerifier
+}
+
+func (a authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if a.verifier == nil || isInfrastructureMethod(fullMethod) || a.conf.IsPublic(fullMethod) {
+		return ctx, nil
+	}
+
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationMetadata); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	if authorization == "" && !a.conf.ProtectAll {
+		return ctx, nil
+	}
+
+	raw, err := auth.ParseBearerToken(authorization)
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, "bearer token required")
+	}
+
+	token, err := a.verifier.Verify(ctx, raw)
+	if err != nil {
+		logg.Root().
+			WithContext(ctx).
+			WithField("grpc_method", fullMethod).
+			WithError(err).
+			Warn("Rejected call with an invalid token")
+
+		return ctx, status.Error(codes.Unauthenticated, "invalid or expired token")
+	}
+
+	return ContextWithSubject(ctx, token.Subject), nil
+}
+
+func (a authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, withContext(ss, ctx))
+}
+
+func isInfrastructureMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, healthServicePrefix) || strings.HasPrefix(fullMethod, reflectionPrefix)
+}
+
+func splitMethod(fullMethod string) (string, string) {
+	trimmed := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+		return trimmed[:i], trimmed[i+1:]
+	}
+
+	return "unknown", trimmed
+}
@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcMetricsComponent = "grpc"
+	grpcMetricsLabels    = []string{"service", "method", "type", "code"}
+)
+
+type GRPCMetrics struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+func newGRPCMetrics() *GRPCMetrics {
+	return &GRPCMetrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "The total number of RPCs completed by the gRPC server.",
+		}, grpcMetricsLabels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "The latency of RPCs handled by the gRPC server.",
+			Buckets: prometheus.DefBuckets,
+		}, grpcMetricsLabels),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "grpc_server_in_flight",
+			Help: "The number of RPCs currently being handled by the gRPC server.",
+		}),
+	}
+}
+
+func (g *GRPCMetrics) Component() string {
+	return grpcMetricsComponent
+}
+
+func (g *GRPCMetrics) RegisterWith(registry *prometheus.Registry) {
+	registry.MustRegister(g.handled, g.duration, g.inFlight)
+}
+
+func (g *GRPCMetrics) observe(fullMethod, kind string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+
+	labels := prometheus.Labels{
+		"service": service,
+		"method":  method,
+		"type":    kind,
+		"code":    status.Code(err).String(),
+	}
+
+	g.handled.With(labels).Inc()
+	g.duration.With(labels).Observe(time.Since(start).Seconds())
+}
+
+func (g *GRPCMetrics) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	g.inFlight.Inc()
+	defer g.inFlight.Dec()
+
+	resp, err := handler(ctx, req)
+	g.observe(info.FullMethod, "unary", start, err)
+
+	return resp, err
+}
+
+func (g *GRPCMetrics) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	g.inFlight.Inc()
+	defer g.inFlight.Dec()
+
+	err := handler(srv, ss)
+	g.observe(info.FullMethod, streamType(info), start, err)
+
+	return err
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
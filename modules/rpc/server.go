@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/types/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+var (
+	ErrFailedToListen = func(addr string) error {
+		return fmt.Errorf("failed to listen on `%s`", addr)
+	}
+)
+
+type Server struct {
+	conf    config.GRPC
+	server  *grpc.Server
+	health  *healthServer
+	metrics *GRPCMetrics
+}
+
+func NewServer(conf config.GRPC, info config.Info, verifier auth.TokenVerifier) *Server {
+	metrics := newGRPCMetrics()
+	authn := authenticator{conf: conf, verifier: verifier}
+
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(requestIDUnary, metrics.unary, loggingUnary, recoveryUnary, authn.unary),
+		grpc.ChainStreamInterceptor(requestIDStream, metrics.stream, loggingStream, recoveryStream, authn.stream),
+		grpc.MaxRecvMsgSize(conf.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(conf.MaxSendMsgSize),
+		grpc.KeepaliveParams(keepalive.ServerParameters{MaxConnectionIdle: conf.MaxConnectionIdle}),
+	}
+
+	if conf.TLS.IsEnabled() {
+		tlsConfig, err := api.NewTLSConfig(conf.TLS)
+		if err != nil {
+			panic(err)
+		}
+
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(opts...)
+	health := newHealthServer(info, conf.HealthInterval)
+
+	healthpb.RegisterHealthServer(server, health)
+
+	if conf.Reflection {
+		reflection.Register(server)
+	}
+
+	return &Server{
+		conf:    conf,
+		server:  server,
+		health:  health,
+		metrics: metrics,
+	}
+}
+
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	s.server.RegisterService(desc, impl)
+}
+
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.server
+}
+
+func (s *Server) Pacer() *GRPCMetrics {
+	return s.metrics
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.conf.ListenAddr())
+	if err != nil {
+		return errwrap.Wrap(ErrFailedToListen(s.conf.ListenAddr()), err)
+	}
+
+	return s.Serve(listener)
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,250 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/modules/heartbeat"
+	"github.com/wgentry22/agora/modules/rpc"
+	"github.com/wgentry22/agora/types/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testVerifier struct{}
+
+func (v testVerifier) Validate(r *http.Request) (string, error) {
+	token, err := v.Verify(r.Context(), strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		return "", err
+	}
+
+	return token.Subject, nil
+}
+
+func (testVerifier) Verify(_ context.Context, raw string) (*auth.Token, error) {
+	if raw == "good" {
+		return &auth.Token{Subject: "user-1"}, nil
+	}
+
+	return nil, errors.New("signature verification failed for key abc123")
+}
+
+type testPulser struct {
+	status heartbeat.HealthCheckStatus
+}
+
+func (t testPulser) Component() string {
+	return "db"
+}
+
+func (t testPulser) Pulse(_ context.Context, pulsec chan<- heartbeat.Pulse) {
+	pulsec <- heartbeat.Pulse{Component: t.Component(), Status: t.status}
+}
+
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agora.test.Echo",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(healthpb.HealthCheckRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					if req.(*healthpb.HealthCheckRequest).GetService() == "panic" {
+						panic("boom")
+					}
+
+					subject, _ := rpc.SubjectFromContext(ctx)
+
+					return &healthpb.HealthCheckRequest{Service: subject}, nil
+				}
+
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/agora.test.Echo/Echo"}, handler)
+			},
+		},
+	},
+}
+
+var _ = Describe("gRPC server", func() {
+	var (
+		server *rpc.Server
+		conn   *grpc.ClientConn
+		served chan error
+		conf   config.GRPC
+	)
+
+	echo := func(ctx context.Context, service string) (*healthpb.HealthCheckRequest, error) {
+		out := new(healthpb.HealthCheckRequest)
+		err := conn.Invoke(ctx, "/agora.test.Echo/Echo", &healthpb.HealthCheckRequest{Service: service}, out)
+
+		return out, err
+	}
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	BeforeEach(func() {
+		conf = config.GRPC{
+			Enabled:        true,
+			ProtectAll:     true,
+			MaxRecvMsgSize: 1 << 20,
+			MaxSendMsgSize: 1 << 20,
+			HealthInterval: 50 * time.Millisecond,
+		}
+	})
+
+	JustBeforeEach(func() {
+		heartbeat.ClearPulsers()
+
+		server = rpc.NewServer(conf, config.Info{Name: "agora-test"}, testVerifier{})
+		server.RegisterService(&echoServiceDesc, struct{}{})
+
+		listener := bufconn.Listen(1 << 20)
+		result := make(chan error, 1)
+		go func(s *rpc.Server) {
+			result <- s.Serve(listener)
+		}(server)
+		served = result
+
+		var err error
+		conn, err = grpc.Dial("bufnet",
+			grpc.WithInsecure(),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			}))
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		_ = conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+
+		heartbeat.ClearPulsers()
+	})
+
+	It("should authenticate calls with the token validator", func() {
+		_, err := echo(context.Background(), "")
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+
+		_, err = echo(withToken("bad"), "")
+		Expect(status.Code(err)).To(Equal(codes.Unauthenticated))
+		Expect(status.Convert(err).Message()).To(Equal("invalid or expired token"))
+
+		out, err := echo(withToken("good"), "")
+		Expect(err).To(BeNil())
+		Expect(out.GetService()).To(Equal("user-1"))
+	})
+
+	Context("when methods are public", func() {
+		BeforeEach(func() {
+			conf.PublicMethods = []string{"/agora.test.Echo/Echo"}
+		})
+
+		It("should skip authentication", func() {
+			out, err := echo(context.Background(), "")
+			Expect(err).To(BeNil())
+			Expect(out.GetService()).To(BeEmpty())
+		})
+	})
+
+	It("should recover from panics with an internal error", func() {
+		_, err := echo(withToken("good"), "panic")
+		Expect(status.Code(err)).To(Equal(codes.Internal))
+	})
+
+	It("should return a request ID header", func() {
+		var header metadata.MD
+		out := new(healthpb.HealthCheckRequest)
+
+		ctx := metadata.AppendToOutgoingContext(withToken("good"), "x-request-id", "rpc-1")
+		Expect(conn.Invoke(ctx, "/agora.test.Echo/Echo", &healthpb.HealthCheckRequest{}, out, grpc.Header(&header))).To(Succeed())
+		Expect(header.Get("x-request-id")).To(Equal([]string{"rpc-1"}))
+	})
+
+	It("should report health from registered pulsers without authentication", func() {
+		client := healthpb.NewHealthClient(conn)
+
+		heartbeat.RegisterPulser(testPulser{status: heartbeat.StatusOK})
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).To(BeNil())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+		heartbeat.RegisterPulser(testPulser{status: heartbeat.StatusCritical})
+		res, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "db"})
+		Expect(err).To(BeNil())
+		Expect(res.GetStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+		_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "cache"})
+		Expect(status.Code(err)).To(Equal(codes.NotFound))
+	})
+
+	It("should report NOT_SERVING to watchers when shutting down", func() {
+		client := healthpb.NewHealthClient(conn)
+
+		watch, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+		Expect(err).To(BeNil())
+
+		first, err := watch.Recv()
+		Expect(err).To(BeNil())
+		Expect(first.GetStatus()).To(Equal(healthpb.HealthCheckResponse_SERVING))
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		Expect(server.Shutdown(ctx)).To(Succeed())
+
+		last, err := watch.Recv()
+		Expect(err).To(BeNil())
+		Expect(last.GetStatus()).To(Equal(healthpb.HealthCheckResponse_NOT_SERVING))
+
+		Eventually(served).Should(Receive(BeNil()))
+	})
+
+	It("should record metrics for handled calls", func() {
+		registry := prometheus.NewRegistry()
+		server.Pacer().RegisterWith(registry)
+
+		_, _ = echo(withToken("good"), "")
+		_, _ = echo(context.Background(), "")
+
+		families, err := registry.Gather()
+		Expect(err).To(BeNil())
+
+		codesSeen := make([]string, 0)
+		for _, family := range families {
+			if family.GetName() != "grpc_server_handled_total" {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "code" {
+						codesSeen = append(codesSeen, label.GetValue())
+					}
+				}
+			}
+		}
+
+		Expect(codesSeen).To(ConsistOf("OK", "Unauthenticated"))
+	})
+})
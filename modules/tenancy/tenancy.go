@@ -0,0 +1,98 @@
+package tenancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wgentry22/agora/modules/api"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var (
+	ErrTenantNotResolved = errors.New("unable to resolve tenant for request")
+	minSubdomainLabels   = 3
+)
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return logg.ContextWithField(context.WithValue(ctx, tenantKey{}, tenant), "tenant", tenant)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+
+	return tenant, ok && tenant != ""
+}
+
+func Middleware(conf config.Tenancy) func(*gin.Context) {
+	return func(c *gin.Context) {
+		tenant, ok := Resolve(conf, c)
+		if !ok {
+			if conf.Required {
+				api.RenderError(c, api.ErrBadRequest(ErrTenantNotResolved.Error()).WithCause(ErrTenantNotResolved))
+
+				return
+			}
+
+			c.Next()
+
+			return
+		}
+
+		c.Set("tenant", tenant)
+		c.Request = c.Request.WithContext(WithTenant(c.Request.Context(), tenant))
+
+		c.Next()
+	}
+}
+
+func Resolve(conf config.Tenancy, c *gin.Context) (string, bool) {
+	for _, source := range conf.Sources {
+		var tenant string
+
+		switch source {
+		case config.TenancySourceClaim:
+			if claim, ok := auth.Claims(c)[conf.Claim]; ok && claim != nil {
+				tenant = fmt.Sprint(claim)
+			}
+		case config.TenancySourceHeader:
+			tenant = c.GetHeader(conf.Header)
+		case config.TenancySourceSubdomain:
+			tenant = subdomain(c.Request.Host)
+		}
+
+		if tenant = strings.TrimSpace(tenant); tenant != "" {
+			return tenant, true
+		}
+	}
+
+	return "", false
+}
+
+func subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < minSubdomainLabels {
+		return ""
+	}
+
+	return labels[0]
+}
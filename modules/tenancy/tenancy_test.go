@@ -0,0 +1,106 @@
+package tenancy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/auth"
+	"github.com/wgentry22/agora/modules/logg"
+	"github.com/wgentry22/agora/modules/tenancy"
+	"github.com/wgentry22/agora/types/config"
+)
+
+var _ = Describe("Tenancy Middleware", func() {
+	var (
+		router *gin.Engine
+		conf   config.Tenancy
+		buf    bytes.Buffer
+	)
+
+	tenantHandler := func(c *gin.Context) {
+		tenant, _ := tenancy.FromContext(c.Request.Context())
+
+		logg.NewLogrusLogger(config.Logging{Level: "info"}).
+			WithWriter(&buf).
+			WithContext(c.Request.Context()).
+			Info("resolved")
+
+		c.String(http.StatusOK, tenant)
+	}
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		return w
+	}
+
+	BeforeEach(func() {
+		buf.Reset()
+		router = gin.New()
+		conf = config.Tenancy{
+			Enabled:  true,
+			Sources:  []config.TenancySource{config.TenancySourceClaim, config.TenancySourceHeader, config.TenancySourceSubdomain},
+			Claim:    "tenant_id",
+			Header:   "X-Tenant-ID",
+			Column:   "tenant_id",
+			Required: true,
+		}
+	})
+
+	Context("when resolving from a token claim", func() {
+		JustBeforeEach(func() {
+			auth.Use(config.Auth{Vendor: config.AuthVendorMock})
+			router.GET("/test", auth.RequiresTokenMiddleware, tenancy.Middleware(conf), tenantHandler)
+		})
+
+		It("should prefer the claim and inject it as a logging field", func() {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+auth.MockToken("alice", map[string]interface{}{"tenant_id": "acme"}))
+			req.Header.Set("X-Tenant-ID", "other")
+
+			w := serve(req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("acme"))
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal(buf.Bytes(), &entry)).To(Succeed())
+			Expect(entry["tenant"]).To(Equal("acme"))
+		})
+	})
+
+	Context("when resolving without a token", func() {
+		JustBeforeEach(func() {
+			router.GET("/test", tenancy.Middleware(conf), tenantHandler)
+		})
+
+		It("should fall back to the header", func() {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("X-Tenant-ID", "globex")
+
+			w := serve(req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("globex"))
+		})
+
+		It("should fall back to the subdomain", func() {
+			req := httptest.NewRequest(http.MethodGet, "http://initech.api.example.com:8123/test", nil)
+
+			w := serve(req)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(Equal("initech"))
+		})
+
+		It("should return 400 BAD REQUEST when a tenant is required but missing", func() {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8123/test", nil)
+
+			w := serve(req)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/errwrap"
+)
+
+const fileChangeOps = fsnotify.Write | fsnotify.Create | fsnotify.Rename
+
+type FileWatcher interface {
+	Watch(errc chan error) <-chan error
+	Changes() <-chan string
+}
+
+type fileWatcher struct {
+	files   map[string]bool
+	changes chan string
+}
+
+func (f *fileWatcher) Watch(errc chan error) <-chan error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		errc <- errwrap.Wrap(ErrFailedToGetWatcher, err)
+
+		return errc
+	}
+
+	dirs := make(map[string]bool, len(f.files))
+	for file := range f.files {
+		dirs[filepath.Dir(file)] = true
+	}
+
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			errc <- errwrap.Wrap(ErrFailedToWatchFile(dir), err)
+
+			return errc
+		}
+	}
+
+	go func(errorChannel chan error) {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Op&fileChangeOps != 0 && f.files[filepath.Clean(event.Name)] {
+					f.changes <- event.Name
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				if err != nil {
+					errorChannel <- err
+				}
+			}
+		}
+	}(errc)
+
+	return errc
+}
+
+func (f *fileWatcher) Changes() <-chan string {
+	return f.changes
+}
+
+func NewFileWatcher(filePaths ...string) FileWatcher {
+	files := make(map[string]bool, len(filePaths))
+
+	for _, filePath := range filePaths {
+		info, err := os.Stat(filePath)
+		if err != nil && os.IsNotExist(err) {
+			panic(ErrPathDoesNotExist(filePath))
+		}
+
+		if info.IsDir() {
+			panic(ErrWatcherGotDirectory)
+		}
+
+		files[filepath.Clean(filePath)] = true
+	}
+
+	return &fileWatcher{files, make(chan string)}
+}
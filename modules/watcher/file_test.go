@@ -0,0 +1,67 @@
+package watcher_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/wgentry22/agora/modules/watcher"
+)
+
+var _ = Describe("FileWatcher", func() {
+	var (
+		dir      string
+		certFile string
+		keyFile  string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "agora-watcher")
+		Expect(err).To(BeNil())
+
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile = filepath.Join(dir, "key.pem")
+
+		Expect(writeDataToFile(certFile, []byte("cert"))).To(Succeed())
+		Expect(writeDataToFile(keyFile, []byte("key"))).To(Succeed())
+	})
+
+	AfterEach(func() {
+		_ = os.RemoveAll(dir)
+	})
+
+	It("should panic when passed a directory", func() {
+		Expect(func() { watcher.NewFileWatcher(certFile, dir) }).To(PanicWith(watcher.ErrWatcherGotDirectory))
+	})
+
+	It("should report writes to watched files", func() {
+		fw := watcher.NewFileWatcher(certFile, keyFile)
+		errors := fw.Watch(make(chan error, 1))
+		Consistently(errors).ShouldNot(Receive())
+
+		Expect(writeDataToFile(keyFile, []byte("rotated"))).To(Succeed())
+		Eventually(fw.Changes()).Should(Receive(Equal(keyFile)))
+	})
+
+	It("should report files replaced by a rename", func() {
+		fw := watcher.NewFileWatcher(certFile)
+		fw.Watch(make(chan error, 1))
+
+		staged := filepath.Join(dir, "cert.pem.tmp")
+		Expect(writeDataToFile(staged, []byte("rotated"))).To(Succeed())
+		Expect(os.Rename(staged, certFile)).To(Succeed())
+
+		Eventually(fw.Changes()).Should(Receive(Equal(certFile)))
+	})
+
+	It("should ignore other files in the same directory", func() {
+		fw := watcher.NewFileWatcher(certFile)
+		fw.Watch(make(chan error, 1))
+
+		Expect(writeDataToFile(filepath.Join(dir, "other.pem"), []byte("other"))).To(Succeed())
+		Consistently(fw.Changes()).ShouldNot(Receive())
+	})
+})
@@ -1,6 +1,7 @@
 package config
 
 import (
+  "errors"
   "fmt"
   "strings"
   "time"
@@ -9,17 +10,33 @@ import (
 )
 
 type API struct {
-  Port       int            `json:"port" yaml:"port" toml:"port"`
-  PathPrefix string         `json:"pathPrefix" yaml:"pathPrefix" toml:"pathPrefix"`
-  info       *Info          `toml:"-"`
-  Timeout    TimeoutOptions `toml:"timeout"`
-  Cors       CORS           `toml:"cors"`
+  Port         int            `json:"port" yaml:"port" toml:"port"`
+  PathPrefix   string         `json:"pathPrefix" yaml:"pathPrefix" toml:"pathPrefix"`
+  info         *Info          `toml:"-"`
+  Timeout      TimeoutOptions `toml:"timeout"`
+  Cors         CORS           `toml:"cors"`
+  OpenAPI      OpenAPI        `toml:"openapi"`
+  ExposeRoutes bool           `toml:"exposeRoutes"`
+  TLS          TLS            `toml:"tls"`
+  RateLimit    RateLimit      `toml:"ratelimit"`
+  AccessLog    AccessLog      `toml:"accessLog"`
+  Metrics      Metrics        `toml:"metrics"`
+  Versions     []APIVersion   `toml:"versions"`
+  Compression  Compression    `toml:"compression"`
+  Idempotency  Idempotency    `toml:"idempotency"`
+  MaxBodySize  int64          `toml:"maxBodySize"`
+  Security     Security       `toml:"security"`
+  Static       Static         `toml:"static"`
+  WebSocket    WebSocket      `toml:"websocket"`
 }
 
 func defaultAPIServer() API {
   return API{
     Port:       defaultAPIPort,
     PathPrefix: defaultAPIPathPrefix,
+    OpenAPI:    defaultOpenAPI(),
+    AccessLog:  defaultAccessLog(),
+    Metrics:    defaultMetrics(),
   }
 }
 
@@ -27,12 +44,20 @@ func (a API) ListenAddr() string {
   return fmt.Sprintf(":%d", a.Port)
 }
 
-func (a API) withInfo(info Info) API {
-  return API{
-    Port:       a.Port,
-    PathPrefix: a.PathPrefix,
-    info:       &info,
+func (a API) Version(name string) (APIVersion, bool) {
+  for _, version := range a.Versions {
+    if version.Name == strings.Trim(name, "/") {
+      return version, true
+    }
   }
+
+  return APIVersion{}, false
+}
+
+func (a API) withInfo(info Info) API {
+  a.info = &info
+
+  return a
 }
 
 func (a API) Info() Info {
@@ -62,6 +87,27 @@ func (a *API) UnmarshalTOML(data interface{}) error {
     a.Port = defaultAPIPort
   }
 
+  if _, ok := dataMap["versions"]; ok {
+    versions, err := parseAPIVersions(dataMap)
+    if err != nil {
+      return err
+    }
+
+    a.Versions = versions
+  }
+
+  if exposeRoutes, ok := dataMap["exposeRoutes"].(bool); ok {
+    a.ExposeRoutes = exposeRoutes
+  }
+
+  if maxBodySize, ok := dataMap["maxBodySize"].(int64); ok {
+    if maxBodySize < 0 {
+      return ErrNegativeMaxBodySize
+    }
+
+    a.MaxBodySize = maxBodySize
+  }
+
   if timeout, ok := dataMap["timeout"]; ok {
     var opts TimeoutOptions
     if err := opts.UnmarshalTOML(timeout); err != nil {
@@ -82,25 +128,115 @@ func (a *API) UnmarshalTOML(data interface{}) error {
     a.Cors = corsConf
   }
 
+  if tlsConf, ok := dataMap["tls"]; ok {
+    var tlsOpts TLS
+    if err := tlsOpts.UnmarshalTOML(tlsConf); err != nil {
+      return err
+    }
+
+    a.TLS = tlsOpts
+  }
+
+  if openAPI, ok := dataMap["openapi"]; ok {
+    var openAPIConf OpenAPI
+    if err := openAPIConf.UnmarshalTOML(openAPI); err != nil {
+      return err
+    }
+
+    a.OpenAPI = openAPIConf
+  } else {
+    a.OpenAPI = defaultOpenAPI()
+  }
+
+  if rateLimit, ok := dataMap["ratelimit"]; ok {
+    var rateLimitConf RateLimit
+    if err := rateLimitConf.UnmarshalTOML(rateLimit); err != nil {
+      return err
+    }
+
+    a.RateLimit = rateLimitConf
+  }
+
+  if accessLog, ok := dataMap["accessLog"]; ok {
+    var accessLogConf AccessLog
+    if err := accessLogConf.UnmarshalTOML(accessLog); err != nil {
+      return err
+    }
+
+    a.AccessLog = accessLogConf
+  } else {
+    a.AccessLog = defaultAccessLog()
+  }
+
+  if metrics, ok := dataMap["metrics"]; ok {
+    var metricsConf Metrics
+    if err := metricsConf.UnmarshalTOML(metrics); err != nil {
+      return err
+    }
+
+    a.Metrics = metricsConf
+  } else {
+    a.Metrics = defaultMetrics()
+  }
+
+  if compression, ok := dataMap["compression"]; ok {
+    var compressionConf Compression
+    if err := compressionConf.UnmarshalTOML(compression); err != nil {
+      return err
+    }
+
+    a.Compression = compressionConf
+  }
+
+  if idempotency, ok := dataMap["idempotency"]; ok {
+    var idempotencyConf Idempotency
+    if err := idempotencyConf.UnmarshalTOML(idempotency); err != nil {
+      return err
+    }
+
+    a.Idempotency = idempotencyConf
+  }
+
+  if security, ok := dataMap["security"]; ok {
+    var securityConf Security
+    if err := securityConf.UnmarshalTOML(security); err != nil {
+      return err
+    }
+
+    a.Security = securityConf
+  }
+
+  if static, ok := dataMap["static"]; ok {
+    var staticConf Static
+    if err := staticConf.UnmarshalTOML(static); err != nil {
+      return err
+    }
+
+    if staticConf.Enabled && staticConf.conflictsWith(a.PathPrefix) {
+      return ErrStaticMountPathConflict(staticConf.MountPath, a.PathPrefix)
+    }
+
+    a.Static = staticConf
+  }
+
+  if webSocket, ok := dataMap["websocket"]; ok {
+    var webSocketConf WebSocket
+    if err := webSocketConf.UnmarshalTOML(webSocket); err != nil {
+      return err
+    }
+
+    a.WebSocket = webSocketConf
+  }
+
   return nil
 }
 
 func (a API) WithInfo(info Info) API {
-  return API{
-    Port:       a.Port,
-    PathPrefix: a.PathPrefix,
-    info:       &info,
-  }
+  return a.withInfo(info)
 }
 
 func (a API) WithDefaultInfo() API {
-  info := defaultInfo()
-
-  return API{
-    Port:       a.Port,
-    PathPrefix: a.PathPrefix,
-    info:       &info,
-  }
+  return a.withInfo(defaultInfo())
 }
 
 func (a API) ShouldRegisterCors() bool {
@@ -111,8 +247,9 @@ func (a API) ShouldRegisterCors() bool {
 }
 
 type TimeoutOptions struct {
-  Read  time.Duration `toml:"read"`
-  Write time.Duration `toml:"write"`
+  Read    time.Duration `toml:"read"`
+  Write   time.Duration `toml:"write"`
+  Handler time.Duration `toml:"handler"`
 }
 
 func defaultTimeoutOptions() TimeoutOptions {
@@ -145,6 +282,10 @@ func (t *TimeoutOptions) UnmarshalTOML(data interface{}) error {
     t.Write = defaultTimeoutDuration
   }
 
+  if handler, ok := dataMap["handler"].(int64); ok && handler > 0 {
+    t.Handler = time.Duration(handler) * time.Millisecond
+  }
+
   return nil
 }
 
@@ -208,3 +349,163 @@ func getStringSliceFromMap(key string, m map[string]interface{}) []string {
 
   return []string{}
 }
+
+var (
+  ErrInvalidSwaggerUIIntegrity = func(in string) error {
+    return fmt.Errorf("integrity `%s` in `api.openapi` must be a `sha256-`, `sha384-` or `sha512-` hash", in)
+  }
+  defaultSwaggerUIAssets = "https://unpkg.com/swagger-ui-dist@3.52.5"
+)
+
+type OpenAPI struct {
+  Enabled                  bool   `toml:"enabled"`
+  SwaggerUI                bool   `toml:"swaggerUI"`
+  Title                    string `toml:"title"`
+  SwaggerUIAssets          string `toml:"swaggerUIAssets"`
+  SwaggerUICSSIntegrity    string `toml:"swaggerUICSSIntegrity"`
+  SwaggerUIBundleIntegrity string `toml:"swaggerUIBundleIntegrity"`
+}
+
+func (o OpenAPI) SwaggerUIAssetsURL() string {
+  if o.SwaggerUIAssets == "" {
+    return defaultSwaggerUIAssets
+  }
+
+  return strings.TrimSuffix(o.SwaggerUIAssets, "/")
+}
+
+func defaultOpenAPI() OpenAPI {
+  return OpenAPI{
+    Enabled: true,
+  }
+}
+
+func (o *OpenAPI) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *o = defaultOpenAPI()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    o.Enabled = enabled
+  }
+
+  if swaggerUI, ok := dataMap["swaggerUI"].(bool); ok {
+    o.SwaggerUI = swaggerUI
+  }
+
+  if title, ok := dataMap["title"].(string); ok {
+    o.Title = title
+  }
+
+  if assets, ok := dataMap["swaggerUIAssets"].(string); ok {
+    o.SwaggerUIAssets = assets
+  }
+
+  if integrity, ok := dataMap["swaggerUICSSIntegrity"].(string); ok {
+    if !isSubresourceIntegrity(integrity) {
+      return ErrInvalidSwaggerUIIntegrity(integrity)
+    }
+
+    o.SwaggerUICSSIntegrity = integrity
+  }
+
+  if integrity, ok := dataMap["swaggerUIBundleIntegrity"].(string); ok {
+    if !isSubresourceIntegrity(integrity) {
+      return ErrInvalidSwaggerUIIntegrity(integrity)
+    }
+
+    o.SwaggerUIBundleIntegrity = integrity
+  }
+
+  return nil
+}
+
+func isSubresourceIntegrity(in string) bool {
+  for _, algorithm := range []string{"sha256-", "sha384-", "sha512-"} {
+    if strings.HasPrefix(in, algorithm) && len(in) > len(algorithm) {
+      return true
+    }
+  }
+
+  return false
+}
+
+var (
+  ErrInvalidAccessLogSampleRate = errors.New("value for `api.accessLog.sampleRate` must be between 0 and 1")
+  ErrNegativeMaxBodySize        = errors.New("value for `api.maxBodySize` must not be negative")
+)
+
+type AccessLog struct {
+  Enabled    bool     `toml:"enabled"`
+  SampleRate float64  `toml:"sampleRate"`
+  Exclude    []string `toml:"exclude"`
+}
+
+func defaultAccessLog() AccessLog {
+  return AccessLog{
+    Enabled:    true,
+    SampleRate: 1,
+    Exclude:    []string{},
+  }
+}
+
+func (l *AccessLog) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *l = defaultAccessLog()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    l.Enabled = enabled
+  }
+
+  switch rate := dataMap["sampleRate"].(type) {
+  case float64:
+    l.SampleRate = rate
+  case int64:
+    l.SampleRate = float64(rate)
+  }
+
+  if l.SampleRate < 0 || l.SampleRate > 1 {
+    return ErrInvalidAccessLogSampleRate
+  }
+
+  if _, ok := dataMap["exclude"]; ok {
+    l.Exclude = getStringSliceFromMap("exclude", dataMap)
+  }
+
+  return nil
+}
+
+type Metrics struct {
+  Enabled bool      `toml:"enabled"`
+  Buckets []float64 `toml:"buckets"`
+}
+
+func defaultMetrics() Metrics {
+  return Metrics{
+    Enabled: true,
+  }
+}
+
+func (m *Metrics) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *m = defaultMetrics()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    m.Enabled = enabled
+  }
+
+  if buckets, ok := dataMap["buckets"].([]interface{}); ok {
+    for _, bucket := range buckets {
+      switch value := bucket.(type) {
+      case float64:
+        m.Buckets = append(m.Buckets, value)
+      case int64:
+        m.Buckets = append(m.Buckets, float64(value))
+      }
+    }
+  }
+
+  return nil
+}
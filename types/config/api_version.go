@@ -0,0 +1,143 @@
+package config
+
+import (
+  "errors"
+  "fmt"
+  "strings"
+  "time"
+
+  "github.com/hashicorp/errwrap"
+  "github.com/pelletier/go-toml"
+)
+
+var (
+  ErrInvalidDeprecationDate = func(key string) error {
+    return fmt.Errorf("value for `%s` must be a date or RFC3339 timestamp", key)
+  }
+  ErrDeprecatedVersionUnknown = func(version string) error {
+    return fmt.Errorf("deprecation declared for unknown api version `%s`", version)
+  }
+  ErrAPIVersionRequired = errors.New("values in `api.versions` must not be empty")
+)
+
+type APIVersion struct {
+  Name        string      `toml:"name"`
+  Prefix      string      `toml:"prefix"`
+  Deprecation Deprecation `toml:"deprecation"`
+}
+
+func NewAPIVersion(name string) APIVersion {
+  prefix := name
+  if !strings.HasPrefix(prefix, "/") {
+    prefix = fmt.Sprintf("/%s", prefix)
+  }
+
+  return APIVersion{
+    Name:   strings.Trim(name, "/"),
+    Prefix: prefix,
+  }
+}
+
+func (v APIVersion) IsDeprecated() bool {
+  return !v.Deprecation.IsZero()
+}
+
+type Deprecation struct {
+  Since  time.Time `toml:"since"`
+  Sunset time.Time `toml:"sunset"`
+  Link   string    `toml:"link"`
+}
+
+func (d Deprecation) IsZero() bool {
+  return d.Since.IsZero() && d.Sunset.IsZero() && d.Link == ""
+}
+
+func (d *Deprecation) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  if since, ok := dataMap["since"]; ok {
+    parsed, parseErr := parseDate("since", since)
+    if parseErr != nil {
+      err = errwrap.Wrap(parseErr, err)
+    } else {
+      d.Since = parsed
+    }
+  }
+
+  if sunset, ok := dataMap["sunset"]; ok {
+    parsed, parseErr := parseDate("sunset", sunset)
+    if parseErr != nil {
+      err = errwrap.Wrap(parseErr, err)
+    } else {
+      d.Sunset = parsed
+    }
+  }
+
+  if link, ok := dataMap["link"].(string); ok {
+    d.Link = link
+  }
+
+  return err
+}
+
+func parseDate(key string, value interface{}) (time.Time, error) {
+  switch date := value.(type) {
+  case time.Time:
+    return date.UTC(), nil
+  case toml.LocalDate:
+    return date.In(time.UTC), nil
+  case toml.LocalDateTime:
+    return date.In(time.UTC), nil
+  case string:
+    if parsed, err := time.Parse("2006-01-02", date); err == nil {
+      return parsed, nil
+    }
+
+    if parsed, err := time.Parse(time.RFC3339, date); err == nil {
+      return parsed.UTC(), nil
+    }
+  }
+
+  return time.Time{}, ErrInvalidDeprecationDate(key)
+}
+
+func parseAPIVersions(dataMap map[string]interface{}) (versions []APIVersion, err error) {
+  for _, name := range getStringSliceFromMap("versions", dataMap) {
+    if strings.Trim(name, "/") == "" {
+      err = errwrap.Wrap(ErrAPIVersionRequired, err)
+
+      continue
+    }
+
+    versions = append(versions, NewAPIVersion(name))
+  }
+
+  deprecations, _ := dataMap["deprecations"].(map[string]interface{})
+
+  for name, deprecationData := range deprecations {
+    deprecationMap, isMap := deprecationData.(map[string]interface{})
+    if !isMap {
+      continue
+    }
+
+    found := false
+
+    for i := range versions {
+      if versions[i].Name != strings.Trim(name, "/") {
+        continue
+      }
+
+      found = true
+
+      if deprecationErr := versions[i].Deprecation.UnmarshalTOML(deprecationMap); deprecationErr != nil {
+        err = errwrap.Wrap(deprecationErr, err)
+      }
+    }
+
+    if !found {
+      err = errwrap.Wrap(ErrDeprecatedVersionUnknown(name), err)
+    }
+  }
+
+  return versions, err
+}
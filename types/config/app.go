@@ -7,14 +7,16 @@ import (
 )
 
 var (
-	defaultApplicationName    = "agora-app"
-	defaultApplicationVersion = NewVersion()
-	defaultApplicationEnv     = Development
-	defaultAPIPathPrefix      = "/v1"
-	defaultAPIPort            = 8123
-	defaultLoggingLevel       = "debug"
-	defaultLoggingOutputPaths = []string{"stdout"}
-	defaultTimeoutDuration    = 5000 * time.Millisecond
+	defaultApplicationName     = "agora-app"
+	defaultApplicationVersion  = NewVersion()
+	defaultApplicationEnv      = Development
+	defaultAPIPathPrefix       = "/v1"
+	defaultAPIPort             = 8123
+	defaultLoggingLevel        = "debug"
+	defaultLoggingOutputPaths  = []string{"stdout"}
+	defaultTimeoutDuration     = 5000 * time.Millisecond
+	defaultAuthCacheTTL        = 5 * time.Minute
+	defaultAuthCacheMaxEntries = 10000
 )
 
 type Application struct {
@@ -24,6 +26,9 @@ type Application struct {
 	db        DB
 	heartbeat Heartbeat
 	broker    Broker
+	auth      Auth
+	tenancy   Tenancy
+	grpc      GRPC
 }
 
 func (a Application) Heartbeat() Heartbeat {
@@ -50,6 +55,18 @@ func (a Application) Broker() Broker {
 	return a.broker
 }
 
+func (a Application) Auth() Auth {
+	return a.auth
+}
+
+func (a Application) Tenancy() Tenancy {
+	return a.tenancy
+}
+
+func (a Application) GRPC() GRPC {
+	return a.grpc
+}
+
 func (a *Application) UnmarshalTOML(data interface{}) (err error) {
 	dataMap := data.(map[string]interface{})
 
@@ -119,6 +136,37 @@ func (a *Application) UnmarshalTOML(data interface{}) (err error) {
 		}
 	}
 
+	if auth, ok := dataMap["auth"]; ok {
+		var authConfig Auth
+		if authErr := authConfig.UnmarshalTOML(auth); authErr != nil {
+			err = errwrap.Wrap(authErr, err)
+		} else {
+			a.auth = authConfig
+		}
+	}
+
+	if tenancy, ok := dataMap["tenancy"]; ok {
+		var tenancyConfig Tenancy
+		if tenancyErr := tenancyConfig.UnmarshalTOML(tenancy); tenancyErr != nil {
+			err = errwrap.Wrap(tenancyErr, err)
+		} else {
+			a.tenancy = tenancyConfig
+		}
+	} else {
+		a.tenancy = defaultTenancy()
+	}
+
+	if grpc, ok := dataMap["grpc"]; ok {
+		var grpcConfig GRPC
+		if grpcErr := grpcConfig.UnmarshalTOML(grpc); grpcErr != nil {
+			err = errwrap.Wrap(grpcErr, err)
+		} else {
+			a.grpc = grpcConfig
+		}
+	} else {
+		a.grpc = defaultGRPC()
+	}
+
 	return err
 }
 
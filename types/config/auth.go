@@ -2,6 +2,9 @@ package config
 
 import (
   "errors"
+  "time"
+
+  "github.com/hashicorp/errwrap"
 )
 
 type AuthVendor int8
@@ -30,17 +33,90 @@ func (a AuthVendor) String() string {
 func (a *AuthVendor) UnmarshalTOML(data interface{}) error {
   if val, ok := data.(string); ok {
     found, isKnown := authVendorLookup[val]
-    if isKnown {
-      *a = found
+    if !isKnown {
+      return ErrUnknownAuthVendor
     }
 
-    return ErrUnknownAuthVendor
+    *a = found
+
+    return nil
   }
 
   return ErrAuthVendorRequired
 }
 
 type Auth struct {
-  Vendor AuthVendor             `toml:"vendor"`
-  Args   map[string]interface{} `toml:"args"`
+  Vendor       AuthVendor             `toml:"vendor"`
+  ProtectAll   bool                   `toml:"protectAll"`
+  CheckRevoked bool                   `toml:"checkRevoked"`
+  Cache        AuthCache              `toml:"cache"`
+  Args         map[string]interface{} `toml:"args"`
+}
+
+func (a Auth) IsEnabled() bool {
+  return a.Vendor != AuthVendorUnknown
+}
+
+func (a *Auth) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  var vendor AuthVendor
+  if vendorErr := vendor.UnmarshalTOML(dataMap["vendor"]); vendorErr != nil {
+    err = errwrap.Wrap(vendorErr, err)
+  } else {
+    a.Vendor = vendor
+  }
+
+  if protectAll, ok := dataMap["protectAll"].(bool); ok {
+    a.ProtectAll = protectAll
+  }
+
+  if checkRevoked, ok := dataMap["checkRevoked"].(bool); ok {
+    a.CheckRevoked = checkRevoked
+  }
+
+  if cache, ok := dataMap["cache"]; ok {
+    var cacheConf AuthCache
+    if cacheErr := cacheConf.UnmarshalTOML(cache); cacheErr != nil {
+      err = errwrap.Wrap(cacheErr, err)
+    } else {
+      a.Cache = cacheConf
+    }
+  }
+
+  if args, ok := dataMap["args"].(map[string]interface{}); ok {
+    a.Args = args
+  } else {
+    a.Args = make(map[string]interface{})
+  }
+
+  return err
+}
+
+type AuthCache struct {
+  Enabled    bool          `toml:"enabled"`
+  TTL        time.Duration `toml:"ttl"`
+  MaxEntries int           `toml:"maxEntries"`
+}
+
+func (a *AuthCache) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    a.Enabled = enabled
+  }
+
+  if ttl, ok := dataMap["ttl"].(int64); ok && ttl > 0 {
+    a.TTL = time.Duration(ttl) * time.Millisecond
+  } else {
+    a.TTL = defaultAuthCacheTTL
+  }
+
+  if maxEntries, ok := dataMap["maxEntries"].(int64); ok && maxEntries > 0 {
+    a.MaxEntries = int(maxEntries)
+  } else {
+    a.MaxEntries = defaultAuthCacheMaxEntries
+  }
+
+  return nil
 }
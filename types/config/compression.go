@@ -0,0 +1,109 @@
+package config
+
+import (
+  "fmt"
+
+  "github.com/hashicorp/errwrap"
+)
+
+type CompressionAlgorithm int8
+
+const (
+  CompressionUnknown CompressionAlgorithm = iota
+  CompressionGzip
+  CompressionBrotli
+)
+
+var (
+  ErrUnknownCompressionAlgorithm = func(in string) error {
+    return fmt.Errorf("unknown compression algorithm `%s`", in)
+  }
+  compressionAlgorithmDisplay = []string{"unknown", "gzip", "br"}
+  compressionAlgorithmLookup  = map[string]CompressionAlgorithm{
+    "unknown": CompressionUnknown,
+    "gzip":    CompressionGzip,
+    "br":      CompressionBrotli,
+    "brotli":  CompressionBrotli,
+  }
+  defaultCompressionMinSize      = 1024
+  defaultCompressionContentTypes = []string{
+    "application/json",
+    "application/problem+json",
+    "application/javascript",
+    "text/css",
+    "text/html",
+    "text/plain",
+  }
+)
+
+func (c CompressionAlgorithm) String() string {
+  return compressionAlgorithmDisplay[c]
+}
+
+func ParseCompressionAlgorithm(in string) (CompressionAlgorithm, error) {
+  algorithm, ok := compressionAlgorithmLookup[in]
+  if !ok || algorithm == CompressionUnknown {
+    return CompressionUnknown, ErrUnknownCompressionAlgorithm(in)
+  }
+
+  return algorithm, nil
+}
+
+type Compression struct {
+  Enabled      bool                   `toml:"enabled"`
+  Algorithms   []CompressionAlgorithm `toml:"algorithms"`
+  Level        int                    `toml:"level"`
+  MinSize      int                    `toml:"minSize"`
+  ContentTypes []string               `toml:"contentTypes"`
+}
+
+func defaultCompression() Compression {
+  contentTypes := make([]string, len(defaultCompressionContentTypes))
+  copy(contentTypes, defaultCompressionContentTypes)
+
+  return Compression{
+    Enabled:      false,
+    Algorithms:   []CompressionAlgorithm{CompressionBrotli, CompressionGzip},
+    MinSize:      defaultCompressionMinSize,
+    ContentTypes: contentTypes,
+  }
+}
+
+func (c *Compression) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  *c = defaultCompression()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    c.Enabled = enabled
+  }
+
+  if _, ok := dataMap["algorithms"]; ok {
+    algorithms := make([]CompressionAlgorithm, 0)
+
+    for _, val := range getStringSliceFromMap("algorithms", dataMap) {
+      algorithm, algorithmErr := ParseCompressionAlgorithm(val)
+      if algorithmErr != nil {
+        err = errwrap.Wrap(algorithmErr, err)
+      } else {
+        algorithms = append(algorithms, algorithm)
+      }
+    }
+
+    c.Algorithms = algorithms
+  }
+
+  if level, ok := dataMap["level"].(int64); ok {
+    c.Level = int(level)
+  }
+
+  if minSize, ok := dataMap["minSize"].(int64); ok && minSize >= 0 {
+    c.MinSize = int(minSize)
+  }
+
+  if _, ok := dataMap["contentTypes"]; ok {
+    c.ContentTypes = getStringSliceFromMap("contentTypes", dataMap)
+  }
+
+  return err
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	defaultGRPCPort             = 9123
+	defaultGRPCHealthInterval   = 5 * time.Second
+	defaultGRPCMaxMessageSize   = 4 << 20
+	defaultGRPCConnectionIdle   = 5 * time.Minute
+	defaultGRPCShutdownDeadline = 5 * time.Second
+)
+
+type GRPC struct {
+	Enabled           bool          `toml:"enabled"`
+	Port              int           `toml:"port"`
+	ProtectAll        bool          `toml:"protectAll"`
+	PublicMethods     []string      `toml:"publicMethods"`
+	Reflection        bool          `toml:"reflection"`
+	MaxRecvMsgSize    int           `toml:"maxRecvMsgSize"`
+	MaxSendMsgSize    int           `toml:"maxSendMsgSize"`
+	MaxConnectionIdle time.Duration `toml:"maxConnectionIdle"`
+	HealthInterval    time.Duration `toml:"healthInterval"`
+	ShutdownTimeout   time.Duration `toml:"shutdownTimeout"`
+	TLS               TLS           `toml:"tls"`
+}
+
+func defaultGRPC() GRPC {
+	return GRPC{
+		Enabled:           false,
+		Port:              defaultGRPCPort,
+		PublicMethods:     []string{},
+		MaxRecvMsgSize:    defaultGRPCMaxMessageSize,
+		MaxSendMsgSize:    defaultGRPCMaxMessageSize,
+		MaxConnectionIdle: defaultGRPCConnectionIdle,
+		HealthInterval:    defaultGRPCHealthInterval,
+		ShutdownTimeout:   defaultGRPCShutdownDeadline,
+	}
+}
+
+func (g GRPC) ListenAddr() string {
+	return fmt.Sprintf(":%d", g.Port)
+}
+
+func (g GRPC) IsPublic(fullMethod string) bool {
+	for _, method := range g.PublicMethods {
+		if method == fullMethod {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *GRPC) UnmarshalTOML(data interface{}) error {
+	dataMap := data.(map[string]interface{})
+
+	*g = defaultGRPC()
+
+	if enabled, ok := dataMap["enabled"].(bool); ok {
+		g.Enabled = enabled
+	}
+
+	if port, ok := dataMap["port"].(int64); ok {
+		g.Port = int(port)
+	}
+
+	if protectAll, ok := dataMap["protectAll"].(bool); ok {
+		g.ProtectAll = protectAll
+	}
+
+	if _, ok := dataMap["publicMethods"]; ok {
+		g.PublicMethods = getStringSliceFromMap("publicMethods", dataMap)
+	}
+
+	if reflection, ok := dataMap["reflection"].(bool); ok {
+		g.Reflection = reflection
+	}
+
+	if size, ok := dataMap["maxRecvMsgSize"].(int64); ok && size > 0 {
+		g.MaxRecvMsgSize = int(size)
+	}
+
+	if size, ok := dataMap["maxSendMsgSize"].(int64); ok && size > 0 {
+		g.MaxSendMsgSize = int(size)
+	}
+
+	if idle, ok := dataMap["maxConnectionIdle"].(int64); ok && idle > 0 {
+		g.MaxConnectionIdle = time.Duration(idle) * time.Millisecond
+	}
+
+	if interval, ok := dataMap["healthInterval"].(int64); ok && interval > 0 {
+		g.HealthInterval = time.Duration(interval) * time.Millisecond
+	}
+
+	if timeout, ok := dataMap["shutdownTimeout"].(int64); ok && timeout > 0 {
+		g.ShutdownTimeout = time.Duration(timeout) * time.Millisecond
+	}
+
+	if tlsConf, ok := dataMap["tls"]; ok {
+		var tlsOpts TLS
+		if err := tlsOpts.UnmarshalTOML(tlsConf); err != nil {
+			return err
+		}
+
+		g.TLS = tlsOpts
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrIdempotencyRetentionRequired = errors.New("value for `api.idempotency.retention` must be greater than zero")
+	defaultIdempotencyHeader        = "Idempotency-Key"
+	defaultIdempotencyRetention     = 24 * time.Hour
+	defaultIdempotencyLockTimeout   = time.Minute
+)
+
+type Idempotency struct {
+	Enabled     bool          `toml:"enabled"`
+	Header      string        `toml:"header"`
+	Methods     []string      `toml:"methods"`
+	Retention   time.Duration `toml:"retention"`
+	LockTimeout time.Duration `toml:"lockTimeout"`
+}
+
+func defaultIdempotency() Idempotency {
+	return Idempotency{
+		Enabled:     false,
+		Header:      defaultIdempotencyHeader,
+		Methods:     []string{http.MethodPost},
+		Retention:   defaultIdempotencyRetention,
+		LockTimeout: defaultIdempotencyLockTimeout,
+	}
+}
+
+func (i Idempotency) AppliesTo(method string) bool {
+	for _, m := range i.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Idempotency) UnmarshalTOML(data interface{}) error {
+	dataMap := data.(map[string]interface{})
+
+	*i = defaultIdempotency()
+
+	if enabled, ok := dataMap["enabled"].(bool); ok {
+		i.Enabled = enabled
+	}
+
+	if header, ok := dataMap["header"].(string); ok && header != "" {
+		i.Header = header
+	}
+
+	if _, ok := dataMap["methods"]; ok {
+		methods := getStringSliceFromMap("methods", dataMap)
+		for idx, method := range methods {
+			methods[idx] = strings.ToUpper(method)
+		}
+
+		i.Methods = methods
+	}
+
+	if retention, ok := dataMap["retention"].(int64); ok {
+		if retention <= 0 {
+			return ErrIdempotencyRetentionRequired
+		}
+
+		i.Retention = time.Duration(retention) * time.Millisecond
+	}
+
+	if lockTimeout, ok := dataMap["lockTimeout"].(int64); ok && lockTimeout > 0 {
+		i.LockTimeout = time.Duration(lockTimeout) * time.Millisecond
+	}
+
+	return nil
+}
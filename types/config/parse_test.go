@@ -1,10 +1,12 @@
 package config_test
 
 import (
+	"crypto/tls"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pelletier/go-toml"
 	"github.com/wgentry22/agora/types/config"
+	"net/http"
 	"time"
 )
 
@@ -43,6 +45,17 @@ var _ = Describe("Parsing config.Application", func() {
 			Expect(app.API()).To(Equal(config.API{
 				Port:       8123,
 				PathPrefix: "/v1",
+				OpenAPI: config.OpenAPI{
+					Enabled: true,
+				},
+				AccessLog: config.AccessLog{
+					Enabled:    true,
+					SampleRate: 1,
+					Exclude:    []string{},
+				},
+				Metrics: config.Metrics{
+					Enabled: true,
+				},
 			}.WithDefaultInfo()))
 
 			connStr := config.ConnectionString(app.DB())
@@ -62,15 +75,82 @@ env = "qa"
 [api]
 port = 9123
 pathPrefix = "prefix"
+exposeRoutes = true
+maxBodySize = 1048576
+versions = ["v1", "v2"]
+[api.deprecations.v1]
+since = 2026-01-01
+sunset = "2026-12-31T00:00:00Z"
+link = "https://example.com/migrate"
 [api.timeout]
 read = 5678
 write = 1234
+handler = 3000
 [api.cors]
 allow-origins = ["http://localhost:1234"]
 allow-methods = ["GET","PUT","POST","PATCH","DELETE"]
 allow-headers = ["Access-Control-Allow-Origin"]
 expose-headers = ["Access-Control-Allow-Origin"]
 allow-credentials = true
+[api.openapi]
+swaggerUI = true
+title = "Agora API"
+swaggerUIAssets = "/assets/swagger-ui"
+swaggerUICSSIntegrity = "sha384-css"
+swaggerUIBundleIntegrity = "sha384-bundle"
+[api.tls]
+certFile = "/etc/agora/tls/cert.pem"
+keyFile = "/etc/agora/tls/key.pem"
+minVersion = "1.3"
+cipherSuites = ["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"]
+clientCAFile = "/etc/agora/tls/ca.pem"
+requireClientCert = true
+[api.ratelimit]
+enabled = true
+requests = 50
+window = 1000
+key = "apiKey"
+trustedProxies = ["10.0.0.0/8", "192.0.2.10"]
+[api.ratelimit.routes."POST /prefix/login"]
+requests = 5
+burst = 1
+key = "ip"
+[api.accessLog]
+sampleRate = 0.25
+exclude = ["/heartbeat/*"]
+[api.metrics]
+buckets = [0.01, 0.1, 1]
+[api.compression]
+enabled = true
+algorithms = ["gzip"]
+level = 6
+minSize = 512
+contentTypes = ["application/json"]
+[api.idempotency]
+enabled = true
+methods = ["post", "patch"]
+retention = 3600000
+[api.security]
+enabled = true
+frameOptions = "sameorigin"
+[api.security.hsts]
+maxAge = 86400000
+preload = true
+[api.security.csrf]
+enabled = true
+sameSite = "lax"
+sessionCookies = ["session"]
+exclude = ["/webhooks/*"]
+[api.static]
+enabled = true
+dir = "/srv/admin"
+mountPath = "admin"
+spa = true
+maxAge = 60000
+immutable = ["/assets/*"]
+precompressed = true
+[api.websocket]
+allowOrigins = ["https://app.example.com"]
 
 [heartbeat]
 pathPrefix = "/ekg"
@@ -103,6 +183,30 @@ timeout = 250
 buffer_size = 1000
 [broker.args]
 "auto.group.offset" = "smallest"
+
+[auth]
+vendor = "firebase"
+protectAll = true
+checkRevoked = true
+[auth.cache]
+enabled = true
+ttl = 60000
+[auth.args]
+credentialsFile = "/etc/agora/firebase.json"
+
+[tenancy]
+enabled = true
+sources = ["header", "subdomain"]
+header = "X-Customer"
+
+[grpc]
+enabled = true
+port = 9500
+protectAll = true
+publicMethods = ["/agora.Public/Ping"]
+reflection = true
+maxRecvMsgSize = 1024
+shutdownTimeout = 10000
 `)
 		)
 
@@ -134,12 +238,15 @@ buffer_size = 1000
 			}))
 
 			Expect(app.API()).To(Equal(config.API{
-				Port:       9123,
-				PathPrefix: "/prefix",
+				Port:         9123,
+				PathPrefix:   "/prefix",
+				ExposeRoutes: true,
 				Timeout: config.TimeoutOptions{
-					Read:  5678 * time.Millisecond,
-					Write: 1234 * time.Millisecond,
+					Read:    5678 * time.Millisecond,
+					Write:   1234 * time.Millisecond,
+					Handler: 3 * time.Second,
 				},
+				MaxBodySize: 1 << 20,
 				Cors: config.CORS{
 					AllowOrigins:     []string{"http://localhost:1234"},
 					AllowMethods:     []string{"GET", "PUT", "POST", "PATCH", "DELETE"},
@@ -147,6 +254,113 @@ buffer_size = 1000
 					ExposeHeaders:    []string{"Access-Control-Allow-Origin"},
 					AllowCredentials: true,
 				},
+				OpenAPI: config.OpenAPI{
+					Enabled:                  true,
+					SwaggerUI:                true,
+					Title:                    "Agora API",
+					SwaggerUIAssets:          "/assets/swagger-ui",
+					SwaggerUICSSIntegrity:    "sha384-css",
+					SwaggerUIBundleIntegrity: "sha384-bundle",
+				},
+				TLS: config.TLS{
+					CertFile:          "/etc/agora/tls/cert.pem",
+					KeyFile:           "/etc/agora/tls/key.pem",
+					MinVersion:        tls.VersionTLS13,
+					CipherSuites:      []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+					ClientCAFile:      "/etc/agora/tls/ca.pem",
+					RequireClientCert: true,
+				},
+				RateLimit: config.RateLimit{
+					Enabled: true,
+					Default: config.RateLimitRule{
+						Requests: 50,
+						Window:   time.Second,
+						Burst:    50,
+						Key:      config.RateLimitKeyAPIKey,
+					},
+					APIKeyHeader:   "X-API-Key",
+					TrustedProxies: []string{"10.0.0.0/8", "192.0.2.10"},
+					Routes: map[string]config.RateLimitRule{
+						"POST /prefix/login": {
+							Requests: 5,
+							Window:   time.Minute,
+							Burst:    1,
+							Key:      config.RateLimitKeyIP,
+						},
+					},
+				},
+				AccessLog: config.AccessLog{
+					Enabled:    true,
+					SampleRate: 0.25,
+					Exclude:    []string{"/heartbeat/*"},
+				},
+				Metrics: config.Metrics{
+					Enabled: true,
+					Buckets: []float64{0.01, 0.1, 1},
+				},
+				Compression: config.Compression{
+					Enabled:      true,
+					Algorithms:   []config.CompressionAlgorithm{config.CompressionGzip},
+					Level:        6,
+					MinSize:      512,
+					ContentTypes: []string{"application/json"},
+				},
+				Idempotency: config.Idempotency{
+					Enabled:     true,
+					Header:      "Idempotency-Key",
+					Methods:     []string{"POST", "PATCH"},
+					Retention:   time.Hour,
+					LockTimeout: time.Minute,
+				},
+				Security: config.Security{
+					Enabled: true,
+					HSTS: config.HSTS{
+						Enabled:           true,
+						MaxAge:            24 * time.Hour,
+						IncludeSubdomains: true,
+						Preload:           true,
+					},
+					ContentSecurityPolicy: "default-src 'self'; frame-ancestors 'none'",
+					NoSniff:               true,
+					FrameOptions:          "SAMEORIGIN",
+					ReferrerPolicy:        "no-referrer",
+					CSRF: config.CSRF{
+						Enabled:        true,
+						CookieName:     "csrf_token",
+						HeaderName:     "X-CSRF-Token",
+						CookiePath:     "/",
+						Secure:         true,
+						SameSite:       http.SameSiteLaxMode,
+						TTL:            12 * time.Hour,
+						SessionCookies: []string{"session"},
+						Exclude:        []string{"/webhooks/*"},
+					},
+				},
+				Static: config.Static{
+					Enabled:       true,
+					Dir:           "/srv/admin",
+					MountPath:     "/admin",
+					Index:         "index.html",
+					SPA:           true,
+					MaxAge:        time.Minute,
+					Immutable:     []string{"/assets/*"},
+					Precompressed: true,
+				},
+				WebSocket: config.WebSocket{
+					AllowOrigins: []string{"https://app.example.com"},
+				},
+				Versions: []config.APIVersion{
+					{
+						Name:   "v1",
+						Prefix: "/v1",
+						Deprecation: config.Deprecation{
+							Since:  time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+							Sunset: time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+							Link:   "https://example.com/migrate",
+						},
+					},
+					config.NewAPIVersion("v2"),
+				},
 			}.WithInfo(expectedInfo)))
 
 			Expect(app.Heartbeat()).To(Equal(config.Heartbeat{
@@ -173,6 +387,250 @@ buffer_size = 1000
 					"auto.group.offset": "smallest",
 				},
 			}))
+
+			Expect(app.Auth()).To(Equal(config.Auth{
+				Vendor:       config.AuthVendorFirebase,
+				ProtectAll:   true,
+				CheckRevoked: true,
+				Cache: config.AuthCache{
+					Enabled:    true,
+					TTL:        time.Minute,
+					MaxEntries: 10000,
+				},
+				Args: map[string]interface{}{
+					"credentialsFile": "/etc/agora/firebase.json",
+				},
+			}))
+
+			Expect(app.Tenancy()).To(Equal(config.Tenancy{
+				Enabled:  true,
+				Sources:  []config.TenancySource{config.TenancySourceHeader, config.TenancySourceSubdomain},
+				Claim:    "tenant_id",
+				Header:   "X-Customer",
+				Column:   "tenant_id",
+				Required: true,
+			}))
+
+			Expect(app.GRPC()).To(Equal(config.GRPC{
+				Enabled:           true,
+				Port:              9500,
+				ProtectAll:        true,
+				PublicMethods:     []string{"/agora.Public/Ping"},
+				Reflection:        true,
+				MaxRecvMsgSize:    1024,
+				MaxSendMsgSize:    4 << 20,
+				MaxConnectionIdle: 5 * time.Minute,
+				HealthInterval:    5 * time.Second,
+				ShutdownTimeout:   10 * time.Second,
+			}))
+		})
+	})
+
+	Context("when auth vendor is unknown", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[auth]
+vendor = "okta"
+`)
+		)
+
+		It("should return ErrUnknownAuthVendor", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrUnknownAuthVendor.Error()))
+			Expect(app.Auth().IsEnabled()).To(BeFalse())
+		})
+	})
+
+	Context("when api.tls requires client certificates without a client CA", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.tls]
+certFile = "cert.pem"
+keyFile = "key.pem"
+requireClientCert = true
+`)
+		)
+
+		It("should return ErrTLSClientCARequired", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrTLSClientCARequired.Error()))
+		})
+	})
+
+	Context("when api.tls names an unknown cipher suite", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.tls]
+certFile = "cert.pem"
+keyFile = "key.pem"
+cipherSuites = ["TLS_NOT_A_SUITE"]
+`)
+		)
+
+		It("should return ErrUnknownCipherSuite", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrUnknownCipherSuite("TLS_NOT_A_SUITE").Error()))
+		})
+	})
+
+	Context("when api.openapi has an invalid integrity", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.openapi]
+swaggerUI = true
+swaggerUIBundleIntegrity = "md5-bundle"
+`)
+		)
+
+		It("should return ErrInvalidSwaggerUIIntegrity", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrInvalidSwaggerUIIntegrity("md5-bundle").Error()))
+		})
+	})
+
+	Context("when api.ratelimit names an unknown key", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.ratelimit]
+enabled = true
+key = "cookie"
+`)
+		)
+
+		It("should return ErrUnknownRateLimitKey", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrUnknownRateLimitKey("cookie").Error()))
+		})
+	})
+
+	Context("when api.ratelimit trusts an invalid proxy", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.ratelimit]
+enabled = true
+trustedProxies = ["10.0.0.0/33"]
+`)
+		)
+
+		It("should return ErrInvalidTrustedProxy", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrInvalidTrustedProxy("10.0.0.0/33").Error()))
+		})
+	})
+
+	Context("when api.accessLog has an out of range sample rate", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.accessLog]
+sampleRate = 1.5
+`)
+		)
+
+		It("should return ErrInvalidAccessLogSampleRate", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrInvalidAccessLogSampleRate.Error()))
+		})
+	})
+
+	Context("when api.compression names an unknown algorithm", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.compression]
+enabled = true
+algorithms = ["zstd"]
+`)
+		)
+
+		It("should return ErrUnknownCompressionAlgorithm", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrUnknownCompressionAlgorithm("zstd").Error()))
+		})
+	})
+
+	Context("when api.security.csrf has an unknown sameSite", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.security.csrf]
+enabled = true
+sameSite = "sometimes"
+`)
+		)
+
+		It("should return ErrUnknownSameSite", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrUnknownSameSite("sometimes").Error()))
+		})
+	})
+
+	Context("when api.websocket allows an invalid origin", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api.websocket]
+allowOrigins = ["app.example.com"]
+`)
+		)
+
+		It("should return ErrInvalidWebSocketOrigin", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrInvalidWebSocketOrigin("app.example.com").Error()))
+		})
+	})
+
+	Context("when api.static is mounted within the path prefix", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api]
+pathPrefix = "/api"
+[api.static]
+enabled = true
+dir = "/srv/admin"
+mountPath = "/api/admin"
+`)
+		)
+
+		It("should return ErrStaticMountPathConflict", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrStaticMountPathConflict("/api/admin", "/api").Error()))
+		})
+	})
+
+	Context("when api.deprecations names an undeclared version", func() {
+		var (
+			app      config.Application
+			tomlData = []byte(`
+[api]
+versions = ["v2"]
+[api.deprecations.v1]
+sunset = 2026-12-31
+`)
+		)
+
+		It("should return ErrDeprecatedVersionUnknown", func() {
+			err := toml.Unmarshal(tomlData, &app)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(config.ErrDeprecatedVersionUnknown("v1").Error()))
 		})
 	})
 })
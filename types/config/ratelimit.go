@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+type RateLimitKey int8
+
+const (
+	RateLimitKeyUnknown RateLimitKey = iota
+	RateLimitKeyIP
+	RateLimitKeySubject
+	RateLimitKeyAPIKey
+)
+
+var (
+	ErrUnknownRateLimitKey = func(in string) error {
+		return fmt.Errorf("unknown rate limit key `%s`", in)
+	}
+	ErrInvalidTrustedProxy = func(in string) error {
+		return fmt.Errorf("trusted proxy `%s` must be an IP address or CIDR range", in)
+	}
+	ErrRateLimitRequestsRequired = errors.New("value for `requests` must be greater than zero in `api.ratelimit`")
+	rateLimitKeyDisplay          = []string{"unknown", "ip", "subject", "apiKey"}
+	rateLimitKeyLookup           = map[string]RateLimitKey{
+		"unknown": RateLimitKeyUnknown,
+		"ip":      RateLimitKeyIP,
+		"subject": RateLimitKeySubject,
+		"apiKey":  RateLimitKeyAPIKey,
+	}
+	defaultRateLimitRequests     = 100
+	defaultRateLimitWindow       = time.Minute
+	defaultRateLimitAPIKeyHeader = "X-API-Key"
+)
+
+func (r RateLimitKey) String() string {
+	return rateLimitKeyDisplay[r]
+}
+
+func ParseRateLimitKey(in string) (RateLimitKey, error) {
+	key, ok := rateLimitKeyLookup[in]
+	if !ok || key == RateLimitKeyUnknown {
+		return RateLimitKeyUnknown, ErrUnknownRateLimitKey(in)
+	}
+
+	return key, nil
+}
+
+func ParseTrustedProxy(in string) (*net.IPNet, error) {
+	if strings.Contains(in, "/") {
+		_, network, err := net.ParseCIDR(in)
+		if err != nil {
+			return nil, ErrInvalidTrustedProxy(in)
+		}
+
+		return network, nil
+	}
+
+	ip := net.ParseIP(in)
+	if ip == nil {
+		return nil, ErrInvalidTrustedProxy(in)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+type RateLimitRule struct {
+	Requests int           `toml:"requests"`
+	Window   time.Duration `toml:"window"`
+	Burst    int           `toml:"burst"`
+	Key      RateLimitKey  `toml:"key"`
+}
+
+func defaultRateLimitRule() RateLimitRule {
+	return RateLimitRule{
+		Requests: defaultRateLimitRequests,
+		Window:   defaultRateLimitWindow,
+		Burst:    defaultRateLimitRequests,
+		Key:      RateLimitKeyIP,
+	}
+}
+
+func (r RateLimitRule) WithDefaults() RateLimitRule {
+	if r.Window <= 0 {
+		r.Window = defaultRateLimitWindow
+	}
+
+	if r.Burst <= 0 {
+		r.Burst = r.Requests
+	}
+
+	if r.Key == RateLimitKeyUnknown {
+		r.Key = RateLimitKeyIP
+	}
+
+	return r
+}
+
+func (r RateLimitRule) IsZero() bool {
+	return r.Requests == 0
+}
+
+func (r *RateLimitRule) UnmarshalTOML(data interface{}) (err error) {
+	dataMap := data.(map[string]interface{})
+
+	*r = defaultRateLimitRule()
+
+	if requests, ok := dataMap["requests"].(int64); ok {
+		if requests <= 0 {
+			err = errwrap.Wrap(ErrRateLimitRequestsRequired, err)
+		} else {
+			r.Requests = int(requests)
+		}
+	}
+
+	if window, ok := dataMap["window"].(int64); ok && window > 0 {
+		r.Window = time.Duration(window) * time.Millisecond
+	}
+
+	if burst, ok := dataMap["burst"].(int64); ok && burst > 0 {
+		r.Burst = int(burst)
+	} else {
+		r.Burst = r.Requests
+	}
+
+	if key, ok := dataMap["key"].(string); ok {
+		parsed, keyErr := ParseRateLimitKey(key)
+		if keyErr != nil {
+			err = errwrap.Wrap(keyErr, err)
+		} else {
+			r.Key = parsed
+		}
+	}
+
+	return err
+}
+
+type RateLimit struct {
+	Enabled        bool                     `toml:"enabled"`
+	Default        RateLimitRule            `toml:"-"`
+	APIKeyHeader   string                   `toml:"apiKeyHeader"`
+	TrustedProxies []string                 `toml:"trustedProxies"`
+	Routes         map[string]RateLimitRule `toml:"routes"`
+}
+
+func defaultRateLimit() RateLimit {
+	return RateLimit{
+		Enabled:      false,
+		Default:      defaultRateLimitRule(),
+		APIKeyHeader: defaultRateLimitAPIKeyHeader,
+		Routes:       map[string]RateLimitRule{},
+	}
+}
+
+func (r RateLimit) Rule(method, path string) (RateLimitRule, bool) {
+	rule, ok := r.Routes[fmt.Sprintf("%s %s", method, path)]
+
+	return rule, ok
+}
+
+func (r *RateLimit) UnmarshalTOML(data interface{}) (err error) {
+	dataMap := data.(map[string]interface{})
+
+	*r = defaultRateLimit()
+
+	if enabled, ok := dataMap["enabled"].(bool); ok {
+		r.Enabled = enabled
+	}
+
+	if ruleErr := r.Default.UnmarshalTOML(dataMap); ruleErr != nil {
+		err = errwrap.Wrap(ruleErr, err)
+	}
+
+	if header, ok := dataMap["apiKeyHeader"].(string); ok && header != "" {
+		r.APIKeyHeader = header
+	}
+
+	if _, ok := dataMap["trustedProxies"]; ok {
+		r.TrustedProxies = getStringSliceFromMap("trustedProxies", dataMap)
+
+		for _, proxy := range r.TrustedProxies {
+			if _, proxyErr := ParseTrustedProxy(proxy); proxyErr != nil {
+				err = errwrap.Wrap(proxyErr, err)
+			}
+		}
+	}
+
+	if routes, ok := dataMap["routes"].(map[string]interface{}); ok {
+		for route, ruleData := range routes {
+			ruleMap, isMap := ruleData.(map[string]interface{})
+			if !isMap {
+				continue
+			}
+
+			var rule RateLimitRule
+			if ruleErr := rule.UnmarshalTOML(ruleMap); ruleErr != nil {
+				err = errwrap.Wrap(ruleErr, err)
+			}
+
+			r.Routes[route] = rule
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+  "errors"
+  "fmt"
+  "net/http"
+  "strings"
+  "time"
+
+  "github.com/hashicorp/errwrap"
+)
+
+var (
+  ErrUnknownSameSite = func(in string) error {
+    return fmt.Errorf("unknown value for `api.security.csrf.sameSite` `%s`, expected one of strict, lax or none", in)
+  }
+  ErrCSRFTokenTTLRequired = errors.New("value for `api.security.csrf.ttl` must be greater than zero")
+  sameSiteLookup          = map[string]http.SameSite{
+    "strict": http.SameSiteStrictMode,
+    "lax":    http.SameSiteLaxMode,
+    "none":   http.SameSiteNoneMode,
+  }
+  defaultHSTSMaxAge            = 365 * 24 * time.Hour
+  defaultContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'"
+  defaultFrameOptions          = "DENY"
+  defaultReferrerPolicy        = "no-referrer"
+  defaultCSRFCookieName        = "csrf_token"
+  defaultCSRFHeaderName        = "X-CSRF-Token"
+  defaultCSRFTokenTTL          = 12 * time.Hour
+)
+
+type Security struct {
+  Enabled               bool   `toml:"enabled"`
+  HSTS                  HSTS   `toml:"hsts"`
+  ContentSecurityPolicy string `toml:"contentSecurityPolicy"`
+  NoSniff               bool   `toml:"noSniff"`
+  FrameOptions          string `toml:"frameOptions"`
+  ReferrerPolicy        string `toml:"referrerPolicy"`
+  CSRF                  CSRF   `toml:"csrf"`
+}
+
+func defaultSecurity() Security {
+  return Security{
+    HSTS:                  defaultHSTS(),
+    ContentSecurityPolicy: defaultContentSecurityPolicy,
+    NoSniff:               true,
+    FrameOptions:          defaultFrameOptions,
+    ReferrerPolicy:        defaultReferrerPolicy,
+    CSRF:                  defaultCSRF(),
+  }
+}
+
+func (s *Security) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  *s = defaultSecurity()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    s.Enabled = enabled
+  }
+
+  if hsts, ok := dataMap["hsts"]; ok {
+    if hstsErr := s.HSTS.UnmarshalTOML(hsts); hstsErr != nil {
+      err = errwrap.Wrap(hstsErr, err)
+    }
+  }
+
+  if csp, ok := dataMap["contentSecurityPolicy"].(string); ok {
+    s.ContentSecurityPolicy = csp
+  }
+
+  if noSniff, ok := dataMap["noSniff"].(bool); ok {
+    s.NoSniff = noSniff
+  }
+
+  if frameOptions, ok := dataMap["frameOptions"].(string); ok {
+    s.FrameOptions = strings.ToUpper(frameOptions)
+  }
+
+  if referrerPolicy, ok := dataMap["referrerPolicy"].(string); ok {
+    s.ReferrerPolicy = referrerPolicy
+  }
+
+  if csrf, ok := dataMap["csrf"]; ok {
+    if csrfErr := s.CSRF.UnmarshalTOML(csrf); csrfErr != nil {
+      err = errwrap.Wrap(csrfErr, err)
+    }
+  }
+
+  return err
+}
+
+type HSTS struct {
+  Enabled           bool          `toml:"enabled"`
+  MaxAge            time.Duration `toml:"maxAge"`
+  IncludeSubdomains bool          `toml:"includeSubdomains"`
+  Preload           bool          `toml:"preload"`
+}
+
+func defaultHSTS() HSTS {
+  return HSTS{
+    Enabled:           true,
+    MaxAge:            defaultHSTSMaxAge,
+    IncludeSubdomains: true,
+  }
+}
+
+func (h HSTS) HeaderValue() string {
+  value := fmt.Sprintf("max-age=%d", int64(h.MaxAge.Seconds()))
+
+  if h.IncludeSubdomains {
+    value += "; includeSubDomains"
+  }
+
+  if h.Preload {
+    value += "; preload"
+  }
+
+  return value
+}
+
+func (h *HSTS) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *h = defaultHSTS()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    h.Enabled = enabled
+  }
+
+  if maxAge, ok := dataMap["maxAge"].(int64); ok && maxAge >= 0 {
+    h.MaxAge = time.Duration(maxAge) * time.Millisecond
+  }
+
+  if includeSubdomains, ok := dataMap["includeSubdomains"].(bool); ok {
+    h.IncludeSubdomains = includeSubdomains
+  }
+
+  if preload, ok := dataMap["preload"].(bool); ok {
+    h.Preload = preload
+  }
+
+  return nil
+}
+
+type CSRF struct {
+  Enabled        bool          `toml:"enabled"`
+  CookieName     string        `toml:"cookieName"`
+  HeaderName     string        `toml:"headerName"`
+  CookiePath     string        `toml:"cookiePath"`
+  CookieDomain   string        `toml:"cookieDomain"`
+  Secure         bool          `toml:"secure"`
+  SameSite       http.SameSite `toml:"sameSite"`
+  TTL            time.Duration `toml:"ttl"`
+  SessionCookies []string      `toml:"sessionCookies"`
+  Exclude        []string      `toml:"exclude"`
+}
+
+func defaultCSRF() CSRF {
+  return CSRF{
+    CookieName:     defaultCSRFCookieName,
+    HeaderName:     defaultCSRFHeaderName,
+    CookiePath:     "/",
+    Secure:         true,
+    SameSite:       http.SameSiteStrictMode,
+    TTL:            defaultCSRFTokenTTL,
+    SessionCookies: []string{},
+    Exclude:        []string{},
+  }
+}
+
+func (c *CSRF) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  *c = defaultCSRF()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    c.Enabled = enabled
+  }
+
+  if cookieName, ok := dataMap["cookieName"].(string); ok && cookieName != "" {
+    c.CookieName = cookieName
+  }
+
+  if headerName, ok := dataMap["headerName"].(string); ok && headerName != "" {
+    c.HeaderName = headerName
+  }
+
+  if cookiePath, ok := dataMap["cookiePath"].(string); ok && cookiePath != "" {
+    c.CookiePath = cookiePath
+  }
+
+  if cookieDomain, ok := dataMap["cookieDomain"].(string); ok {
+    c.CookieDomain = cookieDomain
+  }
+
+  if secure, ok := dataMap["secure"].(bool); ok {
+    c.Secure = secure
+  }
+
+  if sameSite, ok := dataMap["sameSite"].(string); ok {
+    if found, isKnown := sameSiteLookup[strings.ToLower(sameSite)]; isKnown {
+      c.SameSite = found
+    } else {
+      err = errwrap.Wrap(ErrUnknownSameSite(sameSite), err)
+    }
+  }
+
+  if ttl, ok := dataMap["ttl"].(int64); ok {
+    if ttl <= 0 {
+      err = errwrap.Wrap(ErrCSRFTokenTTLRequired, err)
+    } else {
+      c.TTL = time.Duration(ttl) * time.Millisecond
+    }
+  }
+
+  if _, ok := dataMap["sessionCookies"]; ok {
+    c.SessionCookies = getStringSliceFromMap("sessionCookies", dataMap)
+  }
+
+  if _, ok := dataMap["exclude"]; ok {
+    c.Exclude = getStringSliceFromMap("exclude", dataMap)
+  }
+
+  return err
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+  "errors"
+  "fmt"
+  "strings"
+  "time"
+)
+
+var (
+  ErrStaticMountPathConflict = func(mountPath, pathPrefix string) error {
+    return fmt.Errorf("value for `api.static.mountPath` `%s` must not be within `api.pathPrefix` `%s`", mountPath, pathPrefix)
+  }
+  ErrStaticIndexRequired = errors.New("value for `api.static.index` is expected when `api.static.spa` is enabled")
+  defaultStaticMountPath = "/"
+  defaultStaticIndex     = "index.html"
+  defaultStaticMaxAge    = time.Hour
+)
+
+type Static struct {
+  Enabled       bool          `toml:"enabled"`
+  Dir           string        `toml:"dir"`
+  MountPath     string        `toml:"mountPath"`
+  Index         string        `toml:"index"`
+  SPA           bool          `toml:"spa"`
+  MaxAge        time.Duration `toml:"maxAge"`
+  Immutable     []string      `toml:"immutable"`
+  Precompressed bool          `toml:"precompressed"`
+}
+
+func defaultStatic() Static {
+  return Static{
+    MountPath: defaultStaticMountPath,
+    Index:     defaultStaticIndex,
+    MaxAge:    defaultStaticMaxAge,
+    Immutable: []string{},
+  }
+}
+
+func (s Static) WithDefaults() Static {
+  defaults := defaultStatic()
+
+  if s.MountPath == "" {
+    s.MountPath = defaults.MountPath
+  }
+
+  if s.Index == "" {
+    s.Index = defaults.Index
+  }
+
+  return s
+}
+
+func (s *Static) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *s = defaultStatic()
+
+  if enabled, ok := dataMap["enabled"].(bool); ok {
+    s.Enabled = enabled
+  }
+
+  if dir, ok := dataMap["dir"].(string); ok {
+    s.Dir = dir
+  }
+
+  if mountPath, ok := dataMap["mountPath"].(string); ok && mountPath != "" {
+    if !strings.HasPrefix(mountPath, "/") {
+      mountPath = fmt.Sprintf("/%s", mountPath)
+    }
+
+    s.MountPath = mountPath
+  }
+
+  if index, ok := dataMap["index"].(string); ok {
+    s.Index = strings.TrimLeft(index, "/")
+  }
+
+  if spa, ok := dataMap["spa"].(bool); ok {
+    s.SPA = spa
+  }
+
+  if s.SPA && s.Index == "" {
+    return ErrStaticIndexRequired
+  }
+
+  if maxAge, ok := dataMap["maxAge"].(int64); ok && maxAge >= 0 {
+    s.MaxAge = time.Duration(maxAge) * time.Millisecond
+  }
+
+  if _, ok := dataMap["immutable"]; ok {
+    s.Immutable = getStringSliceFromMap("immutable", dataMap)
+  }
+
+  if precompressed, ok := dataMap["precompressed"].(bool); ok {
+    s.Precompressed = precompressed
+  }
+
+  return nil
+}
+
+func (s Static) conflictsWith(pathPrefix string) bool {
+  if pathPrefix == "" || pathPrefix == "/" {
+    return false
+  }
+
+  mountPath := strings.TrimRight(s.MountPath, "/")
+
+  return mountPath == pathPrefix || strings.HasPrefix(mountPath, strings.TrimRight(pathPrefix, "/")+"/")
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+)
+
+type TenancySource int8
+
+const (
+	TenancySourceUnknown TenancySource = iota
+	TenancySourceClaim
+	TenancySourceHeader
+	TenancySourceSubdomain
+)
+
+var (
+	ErrUnknownTenancySource = func(in string) error {
+		return fmt.Errorf("unknown tenancy source `%s`", in)
+	}
+	ErrTenancySourceRequired = errors.New("at least one value for `tenancy.sources` is expected")
+	tenancySourceDisplay     = []string{"unknown", "claim", "header", "subdomain"}
+	tenancySourceLookup      = map[string]TenancySource{
+		"unknown":   TenancySourceUnknown,
+		"claim":     TenancySourceClaim,
+		"header":    TenancySourceHeader,
+		"subdomain": TenancySourceSubdomain,
+	}
+	defaultTenancyClaim  = "tenant_id"
+	defaultTenancyHeader = "X-Tenant-ID"
+	defaultTenancyColumn = "tenant_id"
+)
+
+func (t TenancySource) String() string {
+	return tenancySourceDisplay[t]
+}
+
+func ParseTenancySource(in string) (TenancySource, error) {
+	source, ok := tenancySourceLookup[in]
+	if !ok || source == TenancySourceUnknown {
+		return TenancySourceUnknown, ErrUnknownTenancySource(in)
+	}
+
+	return source, nil
+}
+
+type Tenancy struct {
+	Enabled  bool            `toml:"enabled"`
+	Sources  []TenancySource `toml:"sources"`
+	Claim    string          `toml:"claim"`
+	Header   string          `toml:"header"`
+	Column   string          `toml:"column"`
+	Required bool            `toml:"required"`
+}
+
+func defaultTenancy() Tenancy {
+	return Tenancy{
+		Enabled:  false,
+		Sources:  []TenancySource{TenancySourceClaim},
+		Claim:    defaultTenancyClaim,
+		Header:   defaultTenancyHeader,
+		Column:   defaultTenancyColumn,
+		Required: true,
+	}
+}
+
+func (t *Tenancy) UnmarshalTOML(data interface{}) (err error) {
+	dataMap := data.(map[string]interface{})
+
+	*t = defaultTenancy()
+
+	if enabled, ok := dataMap["enabled"].(bool); ok {
+		t.Enabled = enabled
+	}
+
+	if _, ok := dataMap["sources"]; ok {
+		sources := make([]TenancySource, 0)
+
+		for _, val := range getStringSliceFromMap("sources", dataMap) {
+			source, sourceErr := ParseTenancySource(val)
+			if sourceErr != nil {
+				err = errwrap.Wrap(sourceErr, err)
+			} else {
+				sources = append(sources, source)
+			}
+		}
+
+		if len(sources) == 0 {
+			err = errwrap.Wrap(ErrTenancySourceRequired, err)
+		}
+
+		t.Sources = sources
+	}
+
+	if claim, ok := dataMap["claim"].(string); ok && claim != "" {
+		t.Claim = claim
+	}
+
+	if header, ok := dataMap["header"].(string); ok && header != "" {
+		t.Header = header
+	}
+
+	if column, ok := dataMap["column"].(string); ok && column != "" {
+		t.Column = column
+	}
+
+	if required, ok := dataMap["required"].(bool); ok {
+		t.Required = required
+	}
+
+	return err
+}
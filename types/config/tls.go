@@ -0,0 +1,108 @@
+package config
+
+import (
+  "crypto/tls"
+  "errors"
+  "fmt"
+
+  "github.com/hashicorp/errwrap"
+)
+
+var (
+  ErrTLSCertificateRequired = errors.New("values for `api.tls.certFile` and `api.tls.keyFile` are expected")
+  ErrTLSClientCARequired    = errors.New("value for `api.tls.clientCAFile` is expected when `api.tls.requireClientCert` is set")
+  ErrUnknownTLSVersion      = func(in string) error {
+    return fmt.Errorf("unknown tls version `%s`", in)
+  }
+  ErrUnknownCipherSuite = func(in string) error {
+    return fmt.Errorf("unknown cipher suite `%s`", in)
+  }
+  tlsVersionLookup = map[string]uint16{
+    "1.0": tls.VersionTLS10,
+    "1.1": tls.VersionTLS11,
+    "1.2": tls.VersionTLS12,
+    "1.3": tls.VersionTLS13,
+  }
+  defaultTLSMinVersion = uint16(tls.VersionTLS12)
+)
+
+type TLS struct {
+  CertFile          string   `toml:"certFile"`
+  KeyFile           string   `toml:"keyFile"`
+  MinVersion        uint16   `toml:"minVersion"`
+  CipherSuites      []uint16 `toml:"cipherSuites"`
+  ClientCAFile      string   `toml:"clientCAFile"`
+  RequireClientCert bool     `toml:"requireClientCert"`
+}
+
+func (t TLS) IsEnabled() bool {
+  return t.CertFile != "" && t.KeyFile != ""
+}
+
+func (t TLS) IsMutual() bool {
+  return t.ClientCAFile != ""
+}
+
+func (t *TLS) UnmarshalTOML(data interface{}) (err error) {
+  dataMap := data.(map[string]interface{})
+
+  certFile, hasCert := dataMap["certFile"].(string)
+  keyFile, hasKey := dataMap["keyFile"].(string)
+
+  if !hasCert || !hasKey || certFile == "" || keyFile == "" {
+    err = errwrap.Wrap(ErrTLSCertificateRequired, err)
+  } else {
+    t.CertFile = certFile
+    t.KeyFile = keyFile
+  }
+
+  if version, ok := dataMap["minVersion"].(string); ok {
+    found, isKnown := tlsVersionLookup[version]
+    if !isKnown {
+      err = errwrap.Wrap(ErrUnknownTLSVersion(version), err)
+    } else {
+      t.MinVersion = found
+    }
+  } else {
+    t.MinVersion = defaultTLSMinVersion
+  }
+
+  if _, ok := dataMap["cipherSuites"]; ok {
+    suites := make([]uint16, 0)
+
+    for _, name := range getStringSliceFromMap("cipherSuites", dataMap) {
+      suite, suiteErr := ParseCipherSuite(name)
+      if suiteErr != nil {
+        err = errwrap.Wrap(suiteErr, err)
+      } else {
+        suites = append(suites, suite)
+      }
+    }
+
+    t.CipherSuites = suites
+  }
+
+  if clientCA, ok := dataMap["clientCAFile"].(string); ok {
+    t.ClientCAFile = clientCA
+  }
+
+  if require, ok := dataMap["requireClientCert"].(bool); ok {
+    t.RequireClientCert = require
+  }
+
+  if t.RequireClientCert && t.ClientCAFile == "" {
+    err = errwrap.Wrap(ErrTLSClientCARequired, err)
+  }
+
+  return err
+}
+
+func ParseCipherSuite(in string) (uint16, error) {
+  for _, suite := range tls.CipherSuites() {
+    if suite.Name == in {
+      return suite.ID, nil
+    }
+  }
+
+  return 0, ErrUnknownCipherSuite(in)
+}
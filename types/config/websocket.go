@@ -0,0 +1,35 @@
+package config
+
+import (
+  "fmt"
+  "net/url"
+)
+
+var (
+  ErrInvalidWebSocketOrigin = func(in string) error {
+    return fmt.Errorf("value `%s` in `api.websocket.allowOrigins` must be `*` or an origin such as `https://example.com`", in)
+  }
+)
+
+type WebSocket struct {
+  AllowOrigins []string `toml:"allowOrigins"`
+}
+
+func (w *WebSocket) UnmarshalTOML(data interface{}) error {
+  dataMap := data.(map[string]interface{})
+
+  *w = WebSocket{AllowOrigins: getStringSliceFromMap("allowOrigins", dataMap)}
+
+  for _, origin := range w.AllowOrigins {
+    if origin == "*" {
+      continue
+    }
+
+    parsed, err := url.Parse(origin)
+    if err != nil || parsed.Scheme == "" || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") {
+      return ErrInvalidWebSocketOrigin(origin)
+    }
+  }
+
+  return nil
+}